@@ -107,6 +107,15 @@ docker_login() {
     print_success "Successfully authenticated with Docker Hub"
 }
 
+# Report whether the working tree has uncommitted changes
+git_dirty() {
+    if [[ -n "$(git status --porcelain 2>/dev/null)" ]]; then
+        echo "true"
+    else
+        echo "false"
+    fi
+}
+
 # Build the Docker image with multi-stage optimization
 build_image() {
     local full_image_name="${DOCKER_USERNAME}/${IMAGE_NAME}:${VERSION}"
@@ -125,6 +134,10 @@ build_image() {
     if docker build \
         --file "$DOCKERFILE_PATH" \
         --tag "$full_image_name" \
+        --build-arg VERSION="$VERSION" \
+        --build-arg COMMIT="$(git rev-parse HEAD 2>/dev/null)" \
+        --build-arg BUILD_TIME="$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+        --build-arg DIRTY="$(git_dirty)" \
         --progress=plain \
         --no-cache \
         .; then
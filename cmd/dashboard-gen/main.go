@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/internal/dashboard"
+	"github.com/iamBelugaa/k8s-demo/internal/database"
+	"github.com/iamBelugaa/k8s-demo/internal/server"
+	"github.com/iamBelugaa/k8s-demo/internal/tracing"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/iamBelugaa/k8s-demo/pkg/runtimetune"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func main() {
+	output := flag.String("output", "", "file to write the dashboard to (defaults to stdout)")
+	flag.Parse()
+
+	if err := run(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "error generating dashboard : %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(output string) error {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	log := logger.NewWithTracing(cfg.ServiceName, cfg.ServiceVersion)
+
+	appMetrics, err := server.NewMetrics(log, nil)
+	if err != nil {
+		return err
+	}
+
+	// The runtime collector reports once limits were read. Without a ratio or
+	// SetMaxProcs this only records them, leaving the runtime untouched.
+	runtimetune.Apply(&runtimetune.Config{})
+
+	// The pool collector only reads sql.DB stats, so the database is opened
+	// without ever connecting to it.
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := appMetrics.RegisterDBStats(db, cfg.DB.Name); err != nil {
+		return err
+	}
+
+	// A provider without an exporter still registers the exporter health
+	// collector, keeping its panels in the dashboard.
+	provider, err := tracing.New(&tracing.TracingConfig{
+		ServiceName:    cfg.ServiceName,
+		ServiceVersion: cfg.ServiceVersion,
+		Environment:    cfg.Environment,
+		Exporter:       &tracing.ExporterConfig{Name: config.ExporterNone},
+		Sampler:        &tracing.SamplerConfig{Name: "always_off"},
+		Log:            log,
+	})
+	if err != nil {
+		return err
+	}
+	defer provider.Shutdown(context.Background())
+
+	if err := appMetrics.Register(provider.Collector()); err != nil {
+		return err
+	}
+
+	descriptors, err := appMetrics.Descriptors(prometheus.DefaultGatherer)
+	if err != nil {
+		return err
+	}
+
+	out, err := dashboard.Marshal(&dashboard.Config{
+		UID:         cfg.ServiceName,
+		Title:       cfg.ServiceName,
+		Descriptors: descriptors,
+	})
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(output, out, 0o644)
+}
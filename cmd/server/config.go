@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+)
+
+func configCommand(g *globals, args []string) error {
+	if len(args) == 0 {
+		return &exitError{code: exitUsage, err: fmt.Errorf("usage: k8s-demo config validate|print|docs [flags]")}
+	}
+
+	switch args[0] {
+	case "validate":
+		return configValidate(g, args[1:])
+	case "print":
+		return configPrint(g, args[1:])
+	case "docs":
+		return configDocs(args[1:])
+	default:
+		return &exitError{code: exitUsage, err: fmt.Errorf("unknown config command %q", args[0])}
+	}
+}
+
+// configValidate loads the configuration as serve would, reporting every
+// invalid setting.
+func configValidate(g *globals, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("config validate", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("configuration is valid (%s)\n", cfg.Checksum())
+	return nil
+}
+
+// configPrint writes the effective configuration with secrets redacted and
+// the source of every value.
+func configPrint(g *globals, args []string) error {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	format := flags.String("format", "text", "text or json")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, value := range cfg.Values() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", value.Key, value.Value, value.Source)
+		}
+		fmt.Fprintf(w, "\nchecksum: %s\n", cfg.Checksum())
+		return w.Flush()
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"checksum": cfg.Checksum(), "settings": cfg.Values()})
+	default:
+		return &exitError{code: exitUsage, err: fmt.Errorf("unknown format %q: expected text or json", *format)}
+	}
+}
+
+// configDocs writes the settings reference or a .env example generated from
+// the AppConfig struct tags.
+func configDocs(args []string) error {
+	flags := flag.NewFlagSet("config docs", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "markdown for the reference table or env for a .env example")
+	output := flags.String("output", "", "file to write instead of stdout")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer) error
+	switch *format {
+	case "markdown":
+		write = config.WriteReference
+	case "env":
+		write = config.WriteEnvExample
+	default:
+		return &exitError{code: exitUsage, err: fmt.Errorf("unknown format %q: expected markdown or env", *format)}
+	}
+
+	if *output == "" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+)
+
+// healthcheckCommand requests the health endpoint of a running server and
+// fails unless it answers 200. Without curl or a shell in the scratch image,
+// it backs the Docker HEALTHCHECK and can serve as a Kubernetes exec probe.
+// It exits 0 or 1 only, as Docker reserves other codes.
+func healthcheckCommand(g *globals, args []string) error {
+	if err := healthcheck(g, args); err != nil {
+		return &exitError{code: exitFailure, err: err}
+	}
+	return nil
+}
+
+func healthcheck(g *globals, args []string) error {
+	flags := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	target := flags.String("url", "", "health endpoint URL, derived from SERVER_API_HOST and -path when empty")
+	path := flags.String("path", "/health", "health endpoint path")
+	socket := flags.String("socket", "", "unix socket to connect to instead of the URL host")
+	timeout := flags.Duration("timeout", 3*time.Second, "time allowed for the whole check")
+	caFile := flags.String("ca", "", "CA certificate used to verify an https endpoint")
+	insecure := flags.Bool("insecure", false, "skip verifying the certificate of an https endpoint")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	endpoint := *target
+	if endpoint == "" {
+		var err error
+		if endpoint, err = localEndpoint(g, *path); err != nil {
+			return err
+		}
+	}
+
+	transport, err := healthcheckTransport(*socket, *caFile, *insecure)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", endpoint, err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("unhealthy: no response within %s", *timeout)
+		}
+		return fmt.Errorf("unhealthy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unhealthy: %s%s", resp.Status, reason(resp.Body))
+	}
+
+	fmt.Println("healthy")
+	return nil
+}
+
+// localEndpoint addresses the server started with the same configuration.
+func localEndpoint(g *globals, path string) (string, error) {
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Web.APIHost)
+	if err != nil {
+		return "", fmt.Errorf("invalid server address %q: %w", cfg.Web.APIHost, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+
+	return (&url.URL{Scheme: "http", Host: net.JoinHostPort(host, port), Path: path}).String(), nil
+}
+
+func healthcheckTransport(socket, caFile string, insecure bool) (*http.Transport, error) {
+	transport := &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: insecure},
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if socket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	}
+	return transport, nil
+}
+
+// reason returns the message of an error response body, if any.
+func reason(body io.Reader) string {
+	var errResponse response.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(body, 64<<10)).Decode(&errResponse); err != nil || errResponse.Message == "" {
+		return ""
+	}
+	return ": " + errResponse.Message
+}
@@ -1,69 +1,221 @@
 package main
 
 import (
-	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/iamBelugaa/k8s-demo/internal/config"
-	"github.com/iamBelugaa/k8s-demo/internal/server"
+	"github.com/iamBelugaa/k8s-demo/internal/tracing"
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/k8sinfo"
 	"github.com/iamBelugaa/k8s-demo/pkg/logger"
 	"github.com/joho/godotenv"
 )
 
+// Exit codes shared by every command.
+const (
+	exitOK = iota
+	// exitFailure reports a failed command or an unhealthy service.
+	exitFailure
+	exitUsage
+	exitConfig
+)
+
+const usage = `usage: k8s-demo [global flags] <command> [flags]
+
+Commands:
+  serve              start the HTTP server (default)
+  migrate            apply pending database migrations
+  healthcheck        check the health of a running server
+  config validate    validate the configuration
+  config print       print the effective configuration
+  config docs        generate the settings reference or a .env example
+  version            print version information
+
+Global flags:
+`
+
+// exitError carries the exit code of a failed command.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// globals are the flags accepted before any command.
+type globals struct {
+	configFile string
+	envFile    string
+	logLevel   string
+	overrides  config.Flags
+}
+
 func main() {
-	if os.Getenv(config.EnvLookupKey) == config.EnvDevelopment {
-		if err := godotenv.Load(); err != nil {
-			fmt.Printf("error loading envs : %+v", err)
-			os.Exit(1)
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	g := &globals{overrides: config.Flags{}}
+
+	flags := flag.NewFlagSet("k8s-demo", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&g.configFile, "config", os.Getenv("CONFIG_FILE"), "YAML or JSON configuration file")
+	flags.StringVar(&g.envFile, "env-file", "", "file of KEY=VALUE environment variables to load, .env in DEVELOPMENT")
+	flags.StringVar(&g.logLevel, "log-level", "", "override LOG_LEVEL")
+	flags.Var(g.overrides, "set", "override a setting as KEY=VALUE, may be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
 		}
+		return exitUsage
 	}
 
-	cfg := config.Load()
-	fmt.Printf("Configuration loaded successfully : %+v \n", cfg)
+	if err := g.loadEnvFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading envs : %v\n", err)
+		return exitConfig
+	}
 
-	log := logger.NewWithTracing(cfg.ServiceName, cfg.ServiceVersion)
-	defer func() {
-		if err := log.Sync(); err != nil {
-			log.Infow("sync error", "error", err)
-		}
-	}()
+	command, args := "serve", flags.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = serveCommand(g, args)
+	case "migrate":
+		err = migrateCommand(g, args)
+	case "healthcheck":
+		err = healthcheckCommand(g, args)
+	case "config":
+		err = configCommand(g, args)
+	case "version":
+		err = versionCommand(g, args)
+	default:
+		err = &exitError{code: exitUsage, err: fmt.Errorf("unknown command %q", command)}
+		flags.Usage()
+	}
 
-	log.Infow("Starting k8s-demo platform with observability...")
+	if err == nil {
+		return exitOK
+	}
 
-	if err := run(log, cfg); err != nil {
-		log.Errorw("startup error", "error", err)
-		if err := log.Sync(); err != nil {
-			log.Infow("sync error", "error", err)
-		}
-		os.Exit(1)
+	fmt.Fprintf(os.Stderr, "error : %v\n", err)
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
 	}
+	return exitFailure
 }
 
-func run(log *logger.Logger, cfg *config.AppConfig) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// loadEnvFile loads the given env file, or .env when present in
+// DEVELOPMENT. Variables already set are not overridden.
+func (g *globals) loadEnvFile() error {
+	if g.envFile != "" {
+		return godotenv.Load(g.envFile)
+	}
+
+	if os.Getenv(config.EnvLookupKey) != config.EnvDevelopment {
+		return nil
+	}
+	if _, err := os.Stat(".env"); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return godotenv.Load()
+}
+
+func (g *globals) sources() *config.Sources {
+	overrides := config.Flags{}
+	for key, value := range g.overrides {
+		overrides[key] = value
+	}
+	if g.logLevel != "" {
+		overrides["LOG_LEVEL"] = g.logLevel
+	}
+	return &config.Sources{File: g.configFile, Flags: overrides}
+}
 
-	srv, err := server.New(ctx, cfg, log)
+// loadConfig loads the configuration, failing with exitConfig.
+func (g *globals) loadConfig() (*config.AppConfig, error) {
+	cfg, err := config.Load(g.sources())
 	if err != nil {
-		return err
+		return nil, &exitError{code: exitConfig, err: err}
 	}
+	return cfg, nil
+}
 
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+// parseFlags parses the flags of a command, failing with exitUsage.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return &exitError{code: exitUsage, err: err}
+	}
+	if flags.NArg() > 0 {
+		return &exitError{code: exitUsage, err: fmt.Errorf("unexpected arguments: %v", flags.Args())}
+	}
+	return nil
+}
 
-	serverErrors := make(chan error, 1)
-	go func() {
-		serverErrors <- srv.Start()
-	}()
+func newLogger(cfg *config.AppConfig) (*logger.Logger, error) {
+	otlp, err := logExport(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring log export : %w", err)
+	}
+
+	log, err := logger.New(&logger.Config{
+		Service:     cfg.ServiceName,
+		Version:     cfg.ServiceVersion,
+		Commit:      buildinfo.Get().ShortCommit(),
+		Fields:      k8sinfo.Get().LogFields(),
+		Level:       cfg.Log.Level,
+		Format:      cfg.Log.Format,
+		Development: cfg.Environment == config.EnvDevelopment,
+		OTLP:        otlp,
+		Sampling: &logger.SamplingConfig{
+			Tick:       cfg.Log.SamplingTick,
+			Initial:    cfg.Log.SamplingInitial,
+			Thereafter: cfg.Log.SamplingThereafter,
+		},
+		RateLimit: &logger.RateLimitConfig{
+			Rate:  cfg.Log.RateLimit,
+			Burst: cfg.Log.RateBurst,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating logger : %w", err)
+	}
+	return log, nil
+}
 
-	select {
-	case err := <-serverErrors:
-		return err
-	case sig := <-shutdown:
-		log.Infow("shutting down server", "signal", sig)
-		return srv.Shutdown(ctx)
+// logExport returns the OTLP log export settings, nil when logs are only
+// written to stdout.
+func logExport(cfg *config.AppConfig) (*logger.OTLPConfig, error) {
+	switch cfg.Log.Exporter {
+	case config.ExporterNone:
+		return nil, nil
+	case config.ExporterOTLP:
+		return &logger.OTLPConfig{
+			Endpoint: cfg.Log.Endpoint,
+			Headers:  cfg.Tracing.Headers,
+			Level:    cfg.Log.ExportLevel,
+			Resource: tracing.NewResource(cfg.ServiceName, cfg.ServiceVersion, cfg.Environment),
+		}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unsupported logs exporter %q: expected %s or %s", cfg.Log.Exporter, config.ExporterOTLP, config.ExporterNone,
+		)
 	}
 }
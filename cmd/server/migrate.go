@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/iamBelugaa/k8s-demo/internal/database"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+func migrateCommand(g *globals, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 0, "time allowed for the migrations, unlimited when 0")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+
+	ctx := logger.NewContext(context.Background(), log)
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := database.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("database status check failed: %w", err)
+	}
+
+	applied, err := database.Migrate(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		log.Infow("No pending migrations")
+		return nil
+	}
+	log.Infow("Migrations completed", "applied", applied)
+	return nil
+}
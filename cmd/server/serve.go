@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/internal/server"
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/iamBelugaa/k8s-demo/pkg/runtimetune"
+)
+
+func serveCommand(g *globals, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("serve", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	cfg, err := g.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	log, err := newLogger(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := log.Sync(); err != nil {
+			log.Infow("sync error", "error", err)
+		}
+	}()
+
+	build := buildinfo.Get()
+	log.Infow("Build information",
+		"version", build.Version,
+		"commit", build.Commit,
+		"dirty", build.Dirty,
+		"build_time", build.BuildTime,
+		"go_version", build.GoVersion,
+	)
+	log.Infow("Configuration loaded successfully", "config", cfg)
+
+	tuneRuntime(log, cfg.Runtime)
+	log.Infow("Starting k8s-demo platform with observability...")
+
+	watcher := config.NewWatcher(&config.WatcherConfig{
+		Sources: g.sources(),
+		Config:  cfg,
+		Log:     log,
+	})
+
+	if err := serve(log, watcher); err != nil {
+		log.Errorw("startup error", "error", err)
+		return err
+	}
+	return nil
+}
+
+func serve(log *logger.Logger, watcher *config.Watcher) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv, err := server.New(ctx, watcher, log)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := watcher.Run(ctx); err != nil {
+			log.Errorw("config reloading disabled", "error", err)
+		}
+	}()
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- srv.Start()
+	}()
+
+	select {
+	case err := <-serverErrors:
+		return err
+	case sig := <-shutdown:
+		log.Infow("shutting down server", "signal", sig)
+		return srv.Shutdown(ctx)
+	}
+}
+
+// tuneRuntime sets GOMAXPROCS and GOMEMLIMIT from the container limits and
+// logs the outcome.
+func tuneRuntime(log *logger.Logger, cfg *config.Runtime) {
+	tuning := runtimetune.Apply(&runtimetune.Config{
+		SetMaxProcs:      cfg.SetMaxProcs,
+		MemoryLimitRatio: cfg.MemoryLimitRatio,
+	})
+	if tuning.LimitsError != "" {
+		log.Warnw("Container limits unavailable, keeping runtime defaults", "error", tuning.LimitsError)
+	}
+
+	var limits runtimetune.Limits
+	if tuning.Limits != nil {
+		limits = *tuning.Limits
+	}
+	log.Infow("Runtime tuned to container limits",
+		"cpu_limit", limits.CPU,
+		"memory_limit_bytes", limits.Memory,
+		"gomaxprocs", tuning.GOMAXPROCS,
+		"gomaxprocs_source", tuning.GOMAXPROCSSource,
+		"gomemlimit_bytes", tuning.GOMEMLIMIT,
+		"gomemlimit_source", tuning.GOMEMLIMITSource,
+	)
+}
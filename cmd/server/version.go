@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+)
+
+// versionCommand prints the build information. It reads no configuration so
+// it works in any environment.
+func versionCommand(_ *globals, args []string) error {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print every field, including module dependencies, as JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	info := buildinfo.Get()
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	dirty := ""
+	if info.Dirty {
+		dirty = "-dirty"
+	}
+	fmt.Printf("%s (commit %s%s, built %s, %s %s)\n",
+		info.Version, info.ShortCommit(), dirty, info.BuildTime, info.GoVersion, info.Platform)
+	return nil
+}
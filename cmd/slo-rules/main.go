@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/internal/slo"
+)
+
+func main() {
+	output := flag.String("output", "", "file to write the rules to (defaults to stdout)")
+	flag.Parse()
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration : %v\n", err)
+		os.Exit(1)
+	}
+
+	rules, err := slo.MarshalRules(cfg.SLOs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error generating slo rules : %+v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(rules)
+		return
+	}
+
+	if err := os.WriteFile(*output, rules, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing slo rules : %+v\n", err)
+		os.Exit(1)
+	}
+}
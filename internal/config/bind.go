@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Setting describes a configuration value as declared by the struct tags of
+// its AppConfig field.
+type Setting struct {
+	Key         string
+	Default     string
+	Fallback    string
+	Description string
+	Section     string
+	Type        string
+	Secret      bool
+	// Reload reports whether a changed value applies without a restart.
+	Reload bool
+
+	value reflect.Value
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	listType            = reflect.TypeFor[List]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Settings lists every setting in declaration order.
+func Settings() []*Setting {
+	return settings(&AppConfig{})
+}
+
+// settings walks cfg, allocating its nested structs, and returns its
+// settings bound to the fields they set.
+func settings(cfg *AppConfig) []*Setting {
+	var all []*Setting
+	walk(reflect.ValueOf(cfg).Elem(), "Service", &all)
+	return all
+}
+
+func walk(v reflect.Value, section string, all *[]*Setting) {
+	for i := range v.NumField() {
+		field, tag := v.Field(i), v.Type().Field(i).Tag
+
+		if name, ok := tag.Lookup("section"); ok {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			walk(field.Elem(), name, all)
+			continue
+		}
+
+		key, ok := tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		*all = append(*all, &Setting{
+			Key:         key,
+			Default:     tag.Get("default"),
+			Fallback:    tag.Get("fallback"),
+			Description: tag.Get("desc"),
+			Section:     section,
+			Type:        typeName(field.Type()),
+			Secret:      tag.Get("secret") == "true",
+			Reload:      tag.Get("reload") == "true",
+			value:       field,
+		})
+	}
+}
+
+// bind sets every field of cfg from its key, its fallback key, then its
+// default, recording where each value came from. Invalid values are recorded
+// and leave the default in place.
+func (l *loader) bind(cfg *AppConfig) {
+	cfg.sources = make(map[string]string)
+
+	for _, setting := range settings(cfg) {
+		value, source, ok := l.lookup(setting.Key)
+		if !ok && setting.Fallback != "" {
+			value, source, ok = l.lookup(setting.Fallback)
+		}
+
+		if ok {
+			if err := setValue(setting.value, value); err != nil {
+				l.fail(setting.Key, value, err)
+			} else {
+				cfg.sources[setting.Key] = source
+				continue
+			}
+		}
+
+		if err := setValue(setting.value, setting.Default); err != nil {
+			panic(fmt.Sprintf("config: invalid default for %s: %v", setting.Key, err))
+		}
+		cfg.sources[setting.Key] = SourceDefault
+	}
+}
+
+// current formats the value of the field the setting is bound to for
+// display. Secret values such as headers may format redacted, so values are
+// compared with equal instead.
+func (s *Setting) current() string {
+	return fmt.Sprint(s.value.Interface())
+}
+
+// equal reports whether s and other are bound to fields holding the same
+// value.
+func (s *Setting) equal(other *Setting) bool {
+	return reflect.DeepEqual(s.value.Interface(), other.value.Interface())
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		// Decode into a zero value so a failed parse leaves no partial state.
+		parsed := reflect.New(field.Type())
+		if err := parsed.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		field.Set(parsed.Elem())
+		return nil
+	}
+
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 500ms or 10s")
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		field.SetBool(parsed)
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %s", field.Type()))
+	}
+	return nil
+}
+
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t == listType:
+		return "list"
+	}
+
+	switch t.Kind() {
+	case reflect.Int:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
@@ -1,65 +1,121 @@
 package config
 
 import (
+	"errors"
 	"time"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
 )
 
 const (
 	EnvProduction  string = "PRODUCTION"
 	EnvDevelopment string = "DEVELOPMENT"
+	EnvStaging     string = "STAGING"
 	EnvLookupKey   string = "ENVIRONMENT"
 )
 
 type Web struct {
-	APIHost         string
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	IdleTimeout     time.Duration
-	ShutdownTimeout time.Duration
+	APIHost         string        `env:"SERVER_API_HOST" default:":8080" desc:"Network interface and port the server binds"`
+	AdminHost       string        `env:"SERVER_ADMIN_HOST" default:"127.0.0.1:9090" desc:"Interface and port of the admin endpoints, kept off the Service (empty disables them)"`
+	ReadTimeout     time.Duration `env:"SERVER_READ_TIMEOUT" reload:"true" default:"10s" desc:"Maximum time to read request headers and body"`
+	WriteTimeout    time.Duration `env:"SERVER_WRITE_TIMEOUT" reload:"true" default:"10s" desc:"Maximum time to write the response"`
+	IdleTimeout     time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"120s" desc:"Maximum time to keep idle connections open"`
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" reload:"true" default:"20s" desc:"Maximum time for graceful shutdown"`
 }
 
 type DB struct {
-	MaxIdleConns int
-	MaxOpenConns int
-	TLS          string
-	Name         string
-	User         string
-	Host         string
-	Password     string
-	Scheme       string
+	MaxIdleConns int    `env:"DB_MAX_IDLE_CONN" default:"5" desc:"Maximum idle connections in the pool"`
+	MaxOpenConns int    `env:"DB_MAX_OPEN_CONN" default:"20" desc:"Maximum open connections"`
+	TLS          string `env:"DB_TLS" default:"disable" desc:"TLS mode: disable, allow, prefer, require, verify-ca or verify-full"`
+	Name         string `env:"DB_NAME" default:"k8s-demo" desc:"Database name"`
+	User         string `env:"DB_USER" default:"postgres" desc:"Database user"`
+	Host         string `env:"DB_HOST" default:"localhost" desc:"Database server hostname or IP"`
+	Password     string `env:"DB_PASSWORD" default:"password" desc:"Database password" secret:"true"`
+	Scheme       string `env:"DB_SCHEME" default:"postgres" desc:"Database URL scheme"`
+}
+
+type Log struct {
+	Level  string `env:"LOG_LEVEL" reload:"true" default:"info" desc:"debug, info, warn or error; changeable at runtime via /admin/log/level"`
+	Format string `env:"LOG_FORMAT" desc:"json or console (defaults to console in DEVELOPMENT)"`
+	// Exporter is otlp or none; exported logs use the OTLP headers of the
+	// traces exporter.
+	Exporter    string `env:"OTEL_LOGS_EXPORTER" default:"none" desc:"otlp also exports logs over OTLP/HTTP"`
+	Endpoint    string `env:"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT" fallback:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://otel-collector:4318" desc:"Logs collector URL"`
+	ExportLevel string `env:"LOG_EXPORT_LEVEL" default:"info" desc:"Minimum level of exported log records"`
+	// Sampling logs the first SamplingInitial entries per level and message
+	// every SamplingTick, then every SamplingThereafter-th; 0 disables it.
+	SamplingInitial    int           `env:"LOG_SAMPLING_INITIAL" reload:"true" default:"100" desc:"Entries per level and message logged each tick before sampling (0 disables)"`
+	SamplingThereafter int           `env:"LOG_SAMPLING_THEREAFTER" reload:"true" default:"100" desc:"Then log every Nth entry for the rest of the tick"`
+	SamplingTick       time.Duration `env:"LOG_SAMPLING_TICK" reload:"true" default:"1s" desc:"Sampling interval"`
+	// RateLimit is the number of entries per second allowed per message,
+	// with bursts of up to RateBurst; 0 disables it.
+	RateLimit float64 `env:"LOG_RATE_LIMIT" reload:"true" default:"0" desc:"Entries per second allowed per message (0 disables)"`
+	RateBurst int     `env:"LOG_RATE_BURST" reload:"true" default:"0" desc:"Burst allowed per message (defaults to the rate)"`
+}
+
+type AccessLog struct {
+	// SampleRate is the fraction of successful requests logged; server
+	// errors are always logged.
+	SampleRate float64 `env:"ACCESS_LOG_SAMPLE_RATE" reload:"true" default:"1" desc:"Fraction of successful requests written to the access log"`
+	// ExcludePaths are never logged, a trailing * matching a path prefix.
+	ExcludePaths List `env:"ACCESS_LOG_EXCLUDE_PATHS" reload:"true" default:"/health,/metrics" desc:"Paths never access logged (trailing * matches a prefix)"`
+}
+
+// Runtime tunes the Go runtime to the container limits read from the cgroup
+// at startup. GOMAXPROCS and GOMEMLIMIT set in the environment take
+// precedence.
+type Runtime struct {
+	SetMaxProcs      bool    `env:"RUNTIME_SET_GOMAXPROCS" default:"true" desc:"Set GOMAXPROCS from the container CPU limit"`
+	MemoryLimitRatio float64 `env:"RUNTIME_MEMORY_LIMIT_RATIO" default:"0.9" desc:"Fraction of the container memory limit used as GOMEMLIMIT (0 disables)"`
 }
 
+// AppConfig is bound from the environment by the struct tags of its fields:
+// env names the variable, default and desc document it, fallback names a
+// variable read when it is unset and secret hides its value. Nested structs
+// are grouped under their section in the generated reference.
 type AppConfig struct {
-	DB             *DB
-	Web            *Web
-	ServiceName    string
-	ServiceVersion string
-	Environment    string
-	JaegerEndpoint string
+	ServiceName    string     `env:"SERVICE_NAME" default:"k8s-demo" desc:"Service identifier for tracing and logging"`
+	ServiceVersion string     `env:"SERVICE_VERSION" desc:"Version reported in telemetry, defaults to the build version"`
+	Environment    string     `env:"ENVIRONMENT" default:"DEVELOPMENT" desc:"DEVELOPMENT, STAGING or PRODUCTION"`
+	SLOs           SLOs       `env:"SLO_OBJECTIVES" default:"/health|99.9|95|300ms" desc:"route|availability%[|latency%|latency threshold];..."`
+	Web            *Web       `section:"Server"`
+	DB             *DB        `section:"Database"`
+	Log            *Log       `section:"Logging"`
+	AccessLog      *AccessLog `section:"Access log"`
+	Tracing        *Tracing   `section:"Tracing"`
+	Runtime        *Runtime   `section:"Runtime"`
+
+	// sources maps each key to the source its value was loaded from.
+	sources map[string]string
 }
 
-func Load() *AppConfig {
-	return &AppConfig{
-		ServiceName:    getEnvOrFallback("SERVICE_NAME", "k8s-demo"),
-		ServiceVersion: getEnvOrFallback("SERVICE_VERSION", "v0.1.0"),
-		Environment:    getEnvOrFallback(EnvLookupKey, EnvDevelopment),
-		JaegerEndpoint: getEnvOrFallback("JAEGER_ENDPOINT", "http://jaeger:4318/v1/traces"),
-		DB: &DB{
-			TLS:          getEnvOrFallback("DB_TLS", "disable"),
-			User:         getEnvOrFallback("DB_USER", "postgres"),
-			Name:         getEnvOrFallback("DB_NAME", "k8s-demo"),
-			Host:         getEnvOrFallback("DB_HOST", "localhost"),
-			Scheme:       getEnvOrFallback("DB_SCHEME", "postgres"),
-			Password:     getEnvOrFallback("DB_PASSWORD", "password"),
-			MaxIdleConns: getEnvIntOrFallback("DB_MAX_IDLE_CONN", 5),
-			MaxOpenConns: getEnvIntOrFallback("DB_MAX_OPEN_CONN", 20),
-		},
-		Web: &Web{
-			APIHost:         getEnvOrFallback("SERVER_API_HOST", ":8080"),
-			ReadTimeout:     getDurationOrFallback("SERVER_READ_TIMEOUT", "10s"),
-			WriteTimeout:    getDurationOrFallback("SERVER_WRITE_TIMEOUT", "10s"),
-			IdleTimeout:     getDurationOrFallback("SERVER_IDLE_TIMEOUT", "120s"),
-			ShutdownTimeout: getDurationOrFallback("SERVER_SHUTDOWN_TIMEOUT", "20s"),
-		},
+// Load reads the configuration from sources and validates it. Every invalid
+// or unknown setting is reported in a single *ValidationError rather than
+// replaced by its default. A nil sources reads the environment only.
+func Load(sources *Sources) (*AppConfig, error) {
+	l, err := newLoader(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &AppConfig{}
+	l.bind(cfg)
+	if cfg.ServiceVersion == "" {
+		cfg.ServiceVersion = buildinfo.Get().Version
+	}
+
+	// Settings that failed to parse hold their default, so validating them
+	// too reports nothing twice.
+	l.unknown()
+	fields := l.errs
+
+	var validationErr *ValidationError
+	if errors.As(cfg.Validate(), &validationErr) {
+		fields = append(fields, validationErr.Fields...)
+	}
+
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
 	}
+	return cfg, nil
 }
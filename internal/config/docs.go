@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteReference writes a markdown table per section of every setting.
+func WriteReference(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Configuration\n\n")
+	b.WriteString("Settings are read, in increasing precedence, from the defaults below, the\n")
+	b.WriteString("optional `-config` YAML or JSON file, the environment and `-set KEY=VALUE` flags.\n")
+	b.WriteString("Settings marked as applying on reload take effect without a restart when the\n")
+	b.WriteString("file changes or the process receives SIGHUP.\n")
+	b.WriteString("This file is generated by `make config-docs`; do not edit it by hand.\n")
+
+	section := ""
+	for _, setting := range Settings() {
+		if setting.Section != section {
+			section = setting.Section
+			fmt.Fprintf(&b, "\n## %s\n\n", section)
+			b.WriteString("| Variable | Type | Default | Description |\n")
+			b.WriteString("|---|---|---|---|\n")
+		}
+
+		description := setting.Description
+		if setting.Fallback != "" {
+			description += fmt.Sprintf(". Falls back to `%s`", setting.Fallback)
+		}
+		if setting.Secret {
+			description += ". Secret, redacted from output"
+		}
+		if setting.Reload {
+			description += ". Applies on reload"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+			setting.Key, setting.Type, markdownDefault(setting), strings.ReplaceAll(description, "|", `\|`))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteEnvExample writes a .env file setting every variable to its default.
+// Secrets are left empty so none is committed by accident.
+func WriteEnvExample(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Generated by `make config-docs`; do not edit it by hand.\n")
+	b.WriteString("# CONFIG_FILE optionally names a YAML or JSON file read for unset variables.\n")
+
+	section := ""
+	for _, setting := range Settings() {
+		if setting.Section != section {
+			section = setting.Section
+			fmt.Fprintf(&b, "\n# %s\n", strings.ToUpper(section))
+		}
+
+		value := setting.Default
+		if setting.Secret {
+			value = ""
+		}
+		fmt.Fprintf(&b, "# %s\n%s=%s\n", setting.Description, setting.Key, value)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownDefault(setting *Setting) string {
+	switch {
+	case setting.Secret && setting.Default != "":
+		return Redacted
+	case setting.Default == "":
+		return ""
+	default:
+		return "`" + strings.ReplaceAll(setting.Default, "|", `\|`) + "`"
+	}
+}
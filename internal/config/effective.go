@@ -0,0 +1,63 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Value is the effective value of a setting and where it was loaded from.
+type Value struct {
+	Key     string `json:"key"`
+	Section string `json:"section"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+	Secret  bool   `json:"secret,omitempty"`
+	Reload  bool   `json:"reloadable,omitempty"`
+}
+
+// Values lists every setting of c in declaration order. Secret values are
+// redacted unless they are unset.
+func (c *AppConfig) Values() []*Value {
+	values := make([]*Value, 0, len(c.sources))
+	for _, setting := range settings(c) {
+		value := setting.current()
+		if setting.Secret && value != "" {
+			value = Redacted
+		}
+
+		source := c.sources[setting.Key]
+		if source == "" {
+			source = SourceDefault
+		}
+
+		values = append(values, &Value{
+			Key:     setting.Key,
+			Section: setting.Section,
+			Value:   value,
+			Source:  source,
+			Secret:  setting.Secret,
+			Reload:  setting.Reload,
+		})
+	}
+	return values
+}
+
+// Checksum identifies the effective configuration, so pods running the same
+// configuration report the same checksum. It covers the raw values, secrets
+// included so that rotating one changes it, but not the sources, which do
+// not change behaviour. It is only served on the admin listener.
+func (c *AppConfig) Checksum() string {
+	hash := sha256.New()
+	for _, setting := range settings(c) {
+		hash.Write([]byte(setting.Key + "="))
+		if setting.Secret {
+			// Secrets may format redacted, so their raw values are hashed.
+			fmt.Fprintf(hash, "%#v", setting.value.Interface())
+		} else {
+			hash.Write([]byte(setting.current()))
+		}
+		hash.Write([]byte("\n"))
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources lists where configuration is read from on top of the defaults, in
+// increasing precedence: the file, the environment, then the flags. Keys are
+// environment variable names everywhere.
+type Sources struct {
+	// File is an optional YAML or JSON file. Nested keys are joined with
+	// underscores, so server.read_timeout sets SERVER_READ_TIMEOUT.
+	File string
+	// Flags hold values given on the command line.
+	Flags Flags
+}
+
+// Flags collects KEY=VALUE settings from a repeatable command-line flag.
+type Flags map[string]string
+
+func (f Flags) String() string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, ",")
+}
+
+func (f Flags) Set(value string) error {
+	key, setting, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected KEY=VALUE")
+	}
+	f[strings.ToUpper(strings.TrimSpace(key))] = setting
+	return nil
+}
+
+// Sources a value can come from, as reported by AppConfig.Values.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+type loader struct {
+	file  map[string]string
+	flags map[string]string
+	used  map[string]bool
+	errs  []*FieldError
+}
+
+func newLoader(sources *Sources) (*loader, error) {
+	l := &loader{file: map[string]string{}, flags: map[string]string{}, used: map[string]bool{}}
+	if sources == nil {
+		return l, nil
+	}
+
+	if sources.File != "" {
+		values, err := readFile(sources.File)
+		if err != nil {
+			return nil, err
+		}
+		l.file = values
+	}
+
+	for key, value := range sources.Flags {
+		l.flags[strings.ToUpper(key)] = value
+	}
+	return l, nil
+}
+
+// lookup returns the value of key and its source from the highest precedence
+// source that sets it. Empty values count as unset.
+func (l *loader) lookup(key string) (string, string, bool) {
+	l.used[key] = true
+
+	if value := l.flags[key]; value != "" {
+		return value, SourceFlag, true
+	}
+	if value := os.Getenv(key); value != "" {
+		return value, SourceEnv, true
+	}
+	if value := l.file[key]; value != "" {
+		return value, SourceFile, true
+	}
+	return "", "", false
+}
+
+func (l *loader) fail(key, value string, err error) {
+	l.errs = append(l.errs, &FieldError{Key: key, Value: value, Err: err})
+}
+
+// unknown reports file and flag keys that no setting read, which are
+// almost always typos.
+func (l *loader) unknown() {
+	for _, values := range []map[string]string{l.file, l.flags} {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			if !l.used[key] {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			l.fail(key, values[key], fmt.Errorf("unknown configuration key"))
+		}
+	}
+}
+
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var document map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".json":
+		err = json.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q: expected .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten(values, "", document)
+	return values, nil
+}
+
+// flatten maps nested keys to environment variable names. Lists are joined
+// with commas, matching the list format of the environment variables.
+func flatten(values map[string]string, prefix string, node any) {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, child := range node {
+			key = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			flatten(values, key, child)
+		}
+	case []any:
+		items := make([]string, len(node))
+		for i, item := range node {
+			items[i] = scalar(item)
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		values[prefix] = scalar(node)
+	}
+}
+
+// scalar formats a decoded value as it would be written in the environment.
+// JSON numbers decode as float64, which fmt would print in exponent form.
+func scalar(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+service_name: from-file
+server:
+  read_timeout: 5s
+  write_timeout: 6s
+db:
+  name: file-db
+`)
+
+	t.Setenv("SERVER_READ_TIMEOUT", "7s")
+	t.Setenv("DB_NAME", "env-db")
+
+	cfg, err := Load(&Sources{File: file, Flags: Flags{"DB_NAME": "flag-db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		source string
+	}{
+		{"SERVICE_NAME", cfg.ServiceName, "from-file", SourceFile},
+		{"SERVER_READ_TIMEOUT", cfg.Web.ReadTimeout, 7 * time.Second, SourceEnv},
+		{"SERVER_WRITE_TIMEOUT", cfg.Web.WriteTimeout, 6 * time.Second, SourceFile},
+		{"DB_NAME", cfg.DB.Name, "flag-db", SourceFlag},
+		{"DB_HOST", cfg.DB.Host, "localhost", SourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+			}
+			if source := cfg.sources[tt.key]; source != tt.source {
+				t.Errorf("%s source = %s, want %s", tt.key, source, tt.source)
+			}
+		})
+	}
+}
+
+func TestLoadFallback(t *testing.T) {
+	t.Setenv("JAEGER_ENDPOINT", "http://legacy:4318")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Tracing.Endpoint != "http://legacy:4318" {
+		t.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT = %s, want the JAEGER_ENDPOINT fallback", cfg.Tracing.Endpoint)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	if cfg, err = Load(nil); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Tracing.Endpoint != "http://collector:4318" {
+		t.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT = %s, want it to win over the fallback", cfg.Tracing.Endpoint)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "yaml nesting and lists",
+			file:    "config.yaml",
+			content: "server:\n  api-host: \":9000\"\naccess_log:\n  exclude_paths: [/health, /metrics]\n",
+			want:    map[string]string{"SERVER_API_HOST": ":9000", "ACCESS_LOG_EXCLUDE_PATHS": "/health,/metrics"},
+		},
+		{
+			name:    "yaml numbers",
+			file:    "config.yml",
+			content: "db_max_open_conn: 1000000\nruntime_memory_limit_ratio: 0.75\n",
+			want:    map[string]string{"DB_MAX_OPEN_CONN": "1000000", "RUNTIME_MEMORY_LIMIT_RATIO": "0.75"},
+		},
+		{
+			name:    "json numbers",
+			file:    "config.json",
+			content: `{"db": {"max_open_conn": 1000000}, "access_log": {"sample_rate": 0.0001}}`,
+			want:    map[string]string{"DB_MAX_OPEN_CONN": "1000000", "ACCESS_LOG_SAMPLE_RATE": "0.0001"},
+		},
+		{
+			name:    "null",
+			file:    "config.json",
+			content: `{"log_format": null}`,
+			want:    map[string]string{"LOG_FORMAT": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := readFile(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(values) != len(tt.want) {
+				t.Errorf("got %v, want %v", values, tt.want)
+			}
+			for key, want := range tt.want {
+				if values[key] != want {
+					t.Errorf("%s = %q, want %q", key, values[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+	}{
+		{"unsupported extension", "config.toml", "a = 1"},
+		{"invalid yaml", "config.yaml", "server: [unclosed"},
+		{"invalid json", "config.json", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readFile(writeFile(t, tt.path, tt.content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := readFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestLoadAggregatesErrors(t *testing.T) {
+	file := writeFile(t, "config.json", `{"db_max_open_conn": 1000000, "server_read_timout": "5s"}`)
+
+	t.Setenv("SERVER_WRITE_TIMEOUT", "soon")
+	t.Setenv("DB_MAX_IDLE_CONN", "many")
+	t.Setenv("ENVIRONMENT", "QA")
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratoi")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "1.5")
+	t.Setenv("OTEL_PROPAGATORS", "tracecontext,b4")
+
+	_, err := Load(&Sources{File: file, Flags: Flags{"LOG_LEVL": "debug"}})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want a *ValidationError", err)
+	}
+
+	got := make(map[string]bool)
+	for _, field := range validationErr.Fields {
+		if got[field.Key] {
+			t.Errorf("%s reported twice", field.Key)
+		}
+		got[field.Key] = true
+	}
+
+	want := []string{
+		"SERVER_WRITE_TIMEOUT",
+		"DB_MAX_IDLE_CONN",
+		"SERVER_READ_TIMOUT",
+		"LOG_LEVL",
+		"ENVIRONMENT",
+		"OTEL_TRACES_SAMPLER",
+		"OTEL_TRACES_SAMPLER_ARG",
+		"OTEL_PROPAGATORS",
+	}
+	for _, key := range want {
+		if !got[key] {
+			t.Errorf("%s not reported in:\n%v", key, err)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d invalid settings, want %d:\n%v", len(got), len(want), err)
+	}
+}
+
+func TestFlagsSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{value: "log_level=debug", key: "LOG_LEVEL", want: "debug"},
+		{value: "SLO_OBJECTIVES=/a|99=x", key: "SLO_OBJECTIVES", want: "/a|99=x"},
+		{value: "DB_NAME=", key: "DB_NAME", want: ""},
+		{value: "LOG_LEVEL", wantErr: true},
+		{value: "=debug", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			flags := Flags{}
+			err := flags.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && flags[tt.key] != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, flags[tt.key], tt.want)
+			}
+		})
+	}
+}
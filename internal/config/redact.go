@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces secret values whenever the configuration is printed or
+// logged.
+const Redacted = "[REDACTED]"
+
+// The method-less copies below let String format every field with %+v
+// without recursing into itself, while nested config pointers still format
+// through their own redacted String.
+type (
+	appConfig AppConfig
+	db        DB
+	web       Web
+	log       Log
+	accessLog AccessLog
+	tracing   Tracing
+	runtime   Runtime
+)
+
+func (c *AppConfig) String() string {
+	return fmt.Sprintf("%+v", appConfig(*c))
+}
+
+func (c *AppConfig) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("service_name", c.ServiceName)
+	enc.AddString("service_version", c.ServiceVersion)
+	enc.AddString("environment", c.Environment)
+	enc.AddString("slos", c.SLOs.String())
+	return errors.Join(
+		enc.AddObject("db", c.DB),
+		enc.AddObject("web", c.Web),
+		enc.AddObject("log", c.Log),
+		enc.AddObject("access_log", c.AccessLog),
+		enc.AddObject("tracing", c.Tracing),
+		enc.AddObject("runtime", c.Runtime),
+	)
+}
+
+func (d *DB) String() string {
+	redacted := db(*d)
+	redacted.Password = redact(redacted.Password)
+	return fmt.Sprintf("%+v", redacted)
+}
+
+func (d *DB) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("host", d.Host)
+	enc.AddString("name", d.Name)
+	enc.AddString("user", d.User)
+	enc.AddString("password", redact(d.Password))
+	enc.AddString("scheme", d.Scheme)
+	enc.AddString("tls", d.TLS)
+	enc.AddInt("max_idle_conns", d.MaxIdleConns)
+	enc.AddInt("max_open_conns", d.MaxOpenConns)
+	return nil
+}
+
+func (w *Web) String() string {
+	return fmt.Sprintf("%+v", web(*w))
+}
+
+func (w *Web) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("api_host", w.APIHost)
+	enc.AddString("admin_host", w.AdminHost)
+	enc.AddDuration("read_timeout", w.ReadTimeout)
+	enc.AddDuration("write_timeout", w.WriteTimeout)
+	enc.AddDuration("idle_timeout", w.IdleTimeout)
+	enc.AddDuration("shutdown_timeout", w.ShutdownTimeout)
+	return nil
+}
+
+func (l *Log) String() string {
+	return fmt.Sprintf("%+v", log(*l))
+}
+
+func (l *Log) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("level", l.Level)
+	enc.AddString("format", l.Format)
+	enc.AddString("exporter", l.Exporter)
+	enc.AddString("endpoint", l.Endpoint)
+	enc.AddString("export_level", l.ExportLevel)
+	enc.AddInt("sampling_initial", l.SamplingInitial)
+	enc.AddInt("sampling_thereafter", l.SamplingThereafter)
+	enc.AddDuration("sampling_tick", l.SamplingTick)
+	enc.AddFloat64("rate_limit", l.RateLimit)
+	enc.AddInt("rate_burst", l.RateBurst)
+	return nil
+}
+
+func (a *AccessLog) String() string {
+	return fmt.Sprintf("%+v", accessLog(*a))
+}
+
+func (a *AccessLog) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddFloat64("sample_rate", a.SampleRate)
+	enc.AddString("exclude_paths", a.ExcludePaths.String())
+	return nil
+}
+
+func (t *Tracing) String() string {
+	return fmt.Sprintf("%+v", tracing(*t))
+}
+
+func (t *Tracing) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddBool("required", t.Required)
+	enc.AddString("exporter", t.Exporter)
+	enc.AddString("protocol", t.Protocol)
+	enc.AddString("endpoint", t.Endpoint)
+	enc.AddString("headers", t.Headers.String())
+	enc.AddString("compression", t.Compression)
+	enc.AddBool("insecure", t.Insecure)
+	enc.AddString("certificate", t.Certificate)
+	enc.AddString("client_certificate", t.ClientCert)
+	enc.AddString("client_key", t.ClientKey)
+	enc.AddString("file_path", t.FilePath)
+	enc.AddString("propagators", t.Propagators.String())
+	enc.AddString("sampler", t.Sampler)
+	enc.AddString("sampler_arg", t.SamplerArg)
+	enc.AddBool("sample_errors", t.SampleErrors)
+	enc.AddString("route_sampling", t.RouteSampling.String())
+	return nil
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
+
+func (r *Runtime) String() string {
+	return fmt.Sprintf("%+v", runtime(*r))
+}
+
+func (r *Runtime) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddBool("set_gomaxprocs", r.SetMaxProcs)
+	enc.AddFloat64("memory_limit_ratio", r.MemoryLimitRatio)
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLO declares the availability and latency objectives of a single route.
+// Availability is the percentage of requests that must not fail with a 5xx
+// and LatencyTarget the percentage that must complete within LatencyThreshold.
+type SLO struct {
+	Route            string
+	Availability     float64
+	LatencyTarget    float64
+	LatencyThreshold time.Duration
+}
+
+// SLOs is parsed from a semicolon separated list of
+// "route|availability[|latency_target|latency_threshold]" entries, for
+// example "/health|99.9|95|300ms;/api/users|99.5".
+type SLOs []*SLO
+
+func (s *SLOs) UnmarshalText(text []byte) error {
+	var slos SLOs
+
+	for entry := range strings.SplitSeq(string(text), ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, "|")
+		if len(parts) != 2 && len(parts) != 4 {
+			return fmt.Errorf("invalid slo %q: expected route|availability[|latency_target|latency_threshold]", entry)
+		}
+
+		slo := &SLO{Route: strings.TrimSpace(parts[0])}
+		if !strings.HasPrefix(slo.Route, "/") {
+			return fmt.Errorf("invalid slo %q: route must start with /", entry)
+		}
+
+		availability, err := parsePercentage(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid slo %q: availability: %w", entry, err)
+		}
+		slo.Availability = availability
+
+		if len(parts) == 4 {
+			target, err := parsePercentage(parts[2])
+			if err != nil {
+				return fmt.Errorf("invalid slo %q: latency target: %w", entry, err)
+			}
+
+			threshold, err := time.ParseDuration(strings.TrimSpace(parts[3]))
+			if err != nil || threshold <= 0 {
+				return fmt.Errorf("invalid slo %q: latency threshold must be a positive duration", entry)
+			}
+
+			slo.LatencyTarget = target
+			slo.LatencyThreshold = threshold
+		}
+
+		slos = append(slos, slo)
+	}
+
+	*s = slos
+	return nil
+}
+
+func (s SLOs) String() string {
+	entries := make([]string, len(s))
+	for i, slo := range s {
+		entries[i] = slo.String()
+	}
+	return strings.Join(entries, ";")
+}
+
+func (s *SLO) String() string {
+	entry := s.Route + "|" + strconv.FormatFloat(s.Availability, 'f', -1, 64)
+	if s.LatencyThreshold > 0 {
+		entry += "|" + strconv.FormatFloat(s.LatencyTarget, 'f', -1, 64) + "|" + s.LatencyThreshold.String()
+	}
+	return entry
+}
+
+func (s SLOs) Lookup(route string) (*SLO, bool) {
+	for _, slo := range s {
+		if slo.Route == route {
+			return slo, true
+		}
+	}
+	return nil, false
+}
+
+func parsePercentage(value string) (float64, error) {
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	if parsed <= 0 || parsed >= 100 {
+		return 0, fmt.Errorf("%v must be between 0 and 100 exclusive", parsed)
+	}
+	return parsed, nil
+}
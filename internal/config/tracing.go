@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExporterOTLP   string = "otlp"
+	ExporterStdout string = "stdout"
+	ExporterFile   string = "file"
+	ExporterNone   string = "none"
+
+	ProtocolHTTP string = "http/protobuf"
+	ProtocolGRPC string = "grpc"
+)
+
+const (
+	SamplingAlways string = "always"
+	SamplingNever  string = "never"
+	SamplingRatio  string = "ratio"
+)
+
+type Tracing struct {
+	Required      bool          `env:"TRACING_REQUIRED" default:"false" desc:"Fail startup when tracing cannot be initialized"`
+	Exporter      string        `env:"OTEL_TRACES_EXPORTER" default:"otlp" desc:"otlp, stdout, file or none"`
+	Protocol      string        `env:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"http/protobuf" desc:"http/protobuf or grpc"`
+	Endpoint      string        `env:"OTEL_EXPORTER_OTLP_ENDPOINT" fallback:"JAEGER_ENDPOINT" default:"http://jaeger:4318" desc:"Collector URL (https enables TLS) or host:port"`
+	Headers       Headers       `env:"OTEL_EXPORTER_OTLP_HEADERS" desc:"Comma separated key=value headers sent to the collector" secret:"true"`
+	Compression   string        `env:"OTEL_EXPORTER_OTLP_COMPRESSION" default:"none" desc:"gzip or none"`
+	Insecure      bool          `env:"OTEL_EXPORTER_OTLP_INSECURE" default:"false" desc:"Disable TLS for a host:port endpoint"`
+	Certificate   string        `env:"OTEL_EXPORTER_OTLP_CERTIFICATE" desc:"CA certificate used to verify the collector"`
+	ClientCert    string        `env:"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE" desc:"Client certificate for mutual TLS"`
+	ClientKey     string        `env:"OTEL_EXPORTER_OTLP_CLIENT_KEY" desc:"Client key for mutual TLS"`
+	FilePath      string        `env:"TRACES_FILE_PATH" default:"traces.jsonl" desc:"Output of the file exporter"`
+	Propagators   List          `env:"OTEL_PROPAGATORS" default:"tracecontext,baggage" desc:"tracecontext, baggage, b3, b3multi, jaeger or none"`
+	Sampler       string        `env:"OTEL_TRACES_SAMPLER" desc:"always_on, always_off, traceidratio or parentbased_* variants"`
+	SamplerArg    string        `env:"OTEL_TRACES_SAMPLER_ARG" desc:"Sampling ratio for the *traceidratio samplers"`
+	SampleErrors  bool          `env:"TRACES_SAMPLE_ERRORS" default:"true" desc:"Export spans ending in error even when unsampled. Every span is then recorded, and the error spans arrive without their unsampled parents and siblings, so backends show them as orphans"`
+	RouteSampling RouteSampling `env:"TRACES_SAMPLER_ROUTES" default:"/health=never,/metrics=never" desc:"Per-route overrides: always, never or a ratio"`
+}
+
+// RouteSamplingRule overrides the sampling decision for request paths
+// matching Pattern. A trailing * matches any path with the given prefix.
+type RouteSamplingRule struct {
+	Pattern  string
+	Decision string
+	Ratio    float64
+}
+
+// RouteSampling is parsed from a comma separated list of "pattern=decision"
+// entries where decision is always, never or a ratio between 0 and 1, for
+// example "/health=never,/metrics=never,/api/*=0.5".
+type RouteSampling []*RouteSamplingRule
+
+func (r *RouteSampling) UnmarshalText(text []byte) error {
+	var rules RouteSampling
+
+	for entry := range strings.SplitSeq(string(text), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, decision, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("invalid route sampling rule %q: expected /path=decision", entry)
+		}
+
+		rule := &RouteSamplingRule{Pattern: strings.TrimSpace(pattern)}
+		switch decision = strings.ToLower(strings.TrimSpace(decision)); decision {
+		case SamplingAlways, SamplingNever:
+			rule.Decision = decision
+		default:
+			ratio, err := strconv.ParseFloat(decision, 64)
+			if err != nil || ratio < 0 || ratio > 1 {
+				return fmt.Errorf("invalid route sampling rule %q: decision must be always, never or a ratio in [0, 1]", entry)
+			}
+			rule.Decision = SamplingRatio
+			rule.Ratio = ratio
+		}
+
+		rules = append(rules, rule)
+	}
+
+	*r = rules
+	return nil
+}
+
+func (r RouteSampling) String() string {
+	rules := make([]string, len(r))
+	for i, rule := range r {
+		rules[i] = rule.String()
+	}
+	return strings.Join(rules, ",")
+}
+
+func (r *RouteSamplingRule) String() string {
+	if r.Decision == SamplingRatio {
+		return r.Pattern + "=" + strconv.FormatFloat(r.Ratio, 'f', -1, 64)
+	}
+	return r.Pattern + "=" + r.Decision
+}
+
+func (r *RouteSamplingRule) Matches(path string) bool {
+	if prefix, ok := strings.CutSuffix(r.Pattern, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == r.Pattern
+}
+
+// Headers is parsed from the OTEL_EXPORTER_OTLP_HEADERS format, a comma
+// separated list of url encoded key=value pairs.
+type Headers map[string]string
+
+func (h *Headers) UnmarshalText(text []byte) error {
+	headers := make(Headers)
+
+	for entry := range strings.SplitSeq(string(text), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid header %q: expected key=value", entry)
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid header %q: %w", key, err)
+		}
+		headers[strings.TrimSpace(key)] = decoded
+	}
+
+	*h = headers
+	return nil
+}
+
+// String lists the header names only, values often being credentials.
+func (h Headers) String() string {
+	entries := make([]string, 0, len(h))
+	for key := range h {
+		entries = append(entries, key+"="+Redacted)
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, ",")
+}
+
+// List is parsed from a comma separated list of values.
+type List []string
+
+func (l *List) UnmarshalText(text []byte) error {
+	var list List
+	for value := range strings.SplitSeq(string(text), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+
+	*l = list
+	return nil
+}
+
+func (l List) String() string {
+	return strings.Join(l, ",")
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldError reports an invalid value for the setting read from Key.
+type FieldError struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s=%q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError aggregates every invalid setting so they can all be fixed
+// at once.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		lines[i] = "  - " + field.Error()
+	}
+	return "invalid configuration:\n" + strings.Join(lines, "\n")
+}
+
+var (
+	environments  = []string{EnvDevelopment, EnvStaging, EnvProduction}
+	dbTLSModes    = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	traceSamplers = []string{
+		"", "always_on", "always_off", "traceidratio",
+		"parentbased_always_on", "parentbased_always_off", "parentbased_traceidratio",
+	}
+	propagators = []string{"tracecontext", "baggage", "b3", "b3multi", "jaeger", "none"}
+)
+
+// Validate checks the values of the configuration against each other and
+// against what the service supports. Keys in the returned errors are the
+// environment variable names.
+func (c *AppConfig) Validate() error {
+	v := &validator{}
+
+	v.check("ENVIRONMENT", c.Environment, slices.Contains(environments, c.Environment),
+		"must be one of "+strings.Join(environments, ", "))
+	v.check("SERVICE_NAME", c.ServiceName, c.ServiceName != "", "must not be empty")
+
+	v.address("SERVER_API_HOST", c.Web.APIHost)
+	if c.Web.AdminHost != "" {
+		v.address("SERVER_ADMIN_HOST", c.Web.AdminHost)
+		v.check("SERVER_ADMIN_HOST", c.Web.AdminHost, c.Web.AdminHost != c.Web.APIHost, "must differ from SERVER_API_HOST")
+	}
+	v.positive("SERVER_READ_TIMEOUT", c.Web.ReadTimeout)
+	v.positive("SERVER_WRITE_TIMEOUT", c.Web.WriteTimeout)
+	v.positive("SERVER_IDLE_TIMEOUT", c.Web.IdleTimeout)
+	v.positive("SERVER_SHUTDOWN_TIMEOUT", c.Web.ShutdownTimeout)
+
+	v.check("DB_HOST", c.DB.Host, c.DB.Host != "", "must not be empty")
+	v.check("DB_NAME", c.DB.Name, c.DB.Name != "", "must not be empty")
+	v.check("DB_TLS", c.DB.TLS, slices.Contains(dbTLSModes, c.DB.TLS), "must be one of "+strings.Join(dbTLSModes, ", "))
+	v.check("DB_MAX_OPEN_CONN", strconv.Itoa(c.DB.MaxOpenConns), c.DB.MaxOpenConns > 0, "must be positive")
+	v.check("DB_MAX_IDLE_CONN", strconv.Itoa(c.DB.MaxIdleConns),
+		c.DB.MaxIdleConns >= 0 && c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "must be between 0 and DB_MAX_OPEN_CONN")
+
+	v.level("LOG_LEVEL", c.Log.Level)
+	v.level("LOG_EXPORT_LEVEL", c.Log.ExportLevel)
+	v.oneOf("LOG_FORMAT", c.Log.Format, "", "json", "console")
+	v.oneOf("OTEL_LOGS_EXPORTER", c.Log.Exporter, ExporterOTLP, ExporterNone)
+	v.check("LOG_SAMPLING_INITIAL", strconv.Itoa(c.Log.SamplingInitial), c.Log.SamplingInitial >= 0, "must not be negative")
+	v.check("LOG_SAMPLING_THEREAFTER", strconv.Itoa(c.Log.SamplingThereafter), c.Log.SamplingThereafter >= 0, "must not be negative")
+	v.check("LOG_RATE_LIMIT", fmt.Sprint(c.Log.RateLimit), c.Log.RateLimit >= 0, "must not be negative")
+	v.check("ACCESS_LOG_SAMPLE_RATE", fmt.Sprint(c.AccessLog.SampleRate),
+		c.AccessLog.SampleRate >= 0 && c.AccessLog.SampleRate <= 1, "must be between 0 and 1")
+
+	v.oneOf("OTEL_TRACES_EXPORTER", c.Tracing.Exporter, ExporterOTLP, ExporterStdout, ExporterFile, ExporterNone)
+	v.oneOf("OTEL_EXPORTER_OTLP_PROTOCOL", c.Tracing.Protocol, ProtocolHTTP, ProtocolGRPC)
+	v.oneOf("OTEL_EXPORTER_OTLP_COMPRESSION", c.Tracing.Compression, "none", "gzip")
+	if c.Tracing.Exporter == ExporterFile {
+		v.check("TRACES_FILE_PATH", c.Tracing.FilePath, c.Tracing.FilePath != "", "must not be empty with the file exporter")
+	}
+	v.oneOf("OTEL_TRACES_SAMPLER", strings.ToLower(strings.TrimSpace(c.Tracing.Sampler)), traceSamplers...)
+	if c.Tracing.SamplerArg != "" {
+		ratio, err := strconv.ParseFloat(c.Tracing.SamplerArg, 64)
+		v.check("OTEL_TRACES_SAMPLER_ARG", c.Tracing.SamplerArg, err == nil && ratio >= 0 && ratio <= 1,
+			"must be a ratio between 0 and 1")
+	}
+	for _, propagator := range c.Tracing.Propagators {
+		v.oneOf("OTEL_PROPAGATORS", strings.ToLower(propagator), propagators...)
+	}
+
+	v.check("RUNTIME_MEMORY_LIMIT_RATIO", fmt.Sprint(c.Runtime.MemoryLimitRatio),
+		c.Runtime.MemoryLimitRatio >= 0 && c.Runtime.MemoryLimitRatio <= 1, "must be between 0 and 1")
+
+	return v.err()
+}
+
+type validator struct {
+	fields []*FieldError
+}
+
+func (v *validator) check(key, value string, ok bool, message string) {
+	if !ok {
+		v.fields = append(v.fields, &FieldError{Key: key, Value: value, Err: errors.New(message)})
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	names := slices.DeleteFunc(slices.Clone(allowed), func(name string) bool { return name == "" })
+	v.check(key, value, slices.Contains(allowed, value), "must be one of "+strings.Join(names, ", "))
+}
+
+func (v *validator) positive(key string, duration time.Duration) {
+	v.check(key, duration.String(), duration > 0, "must be a positive duration")
+}
+
+func (v *validator) level(key, level string) {
+	_, err := zapcore.ParseLevel(level)
+	v.check(key, level, err == nil, "must be one of debug, info, warn, error")
+}
+
+func (v *validator) address(key, address string) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		v.check(key, address, false, "must be host:port")
+		return
+	}
+
+	number, err := strconv.Atoi(port)
+	v.check(key, address, err == nil && number > 0 && number <= 65535, "port must be between 1 and 65535")
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
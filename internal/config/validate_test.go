@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func validConfig(t *testing.T) *AppConfig {
+	t.Helper()
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*AppConfig)
+		// invalid lists the keys expected in the error, none for a valid
+		// configuration.
+		invalid []string
+	}{
+		{name: "defaults", modify: func(*AppConfig) {}},
+		{
+			name:    "environment",
+			modify:  func(c *AppConfig) { c.Environment = "production" },
+			invalid: []string{"ENVIRONMENT"},
+		},
+		{
+			name:    "api host without port",
+			modify:  func(c *AppConfig) { c.Web.APIHost = "localhost" },
+			invalid: []string{"SERVER_API_HOST"},
+		},
+		{
+			name:    "admin host same as api host",
+			modify:  func(c *AppConfig) { c.Web.AdminHost = c.Web.APIHost },
+			invalid: []string{"SERVER_ADMIN_HOST"},
+		},
+		{
+			name:   "admin listener disabled",
+			modify: func(c *AppConfig) { c.Web.AdminHost = "" },
+		},
+		{
+			name:    "port out of range",
+			modify:  func(c *AppConfig) { c.Web.APIHost = ":70000" },
+			invalid: []string{"SERVER_API_HOST"},
+		},
+		{
+			name:    "non-positive timeouts",
+			modify:  func(c *AppConfig) { c.Web.ReadTimeout, c.Web.ShutdownTimeout = 0, -1 },
+			invalid: []string{"SERVER_READ_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT"},
+		},
+		{
+			name:    "idle connections above open connections",
+			modify:  func(c *AppConfig) { c.DB.MaxOpenConns, c.DB.MaxIdleConns = 5, 10 },
+			invalid: []string{"DB_MAX_IDLE_CONN"},
+		},
+		{
+			name:    "log level and format",
+			modify:  func(c *AppConfig) { c.Log.Level, c.Log.Format = "verbose", "xml" },
+			invalid: []string{"LOG_LEVEL", "LOG_FORMAT"},
+		},
+		{
+			name:    "access log sample rate",
+			modify:  func(c *AppConfig) { c.AccessLog.SampleRate = 1.5 },
+			invalid: []string{"ACCESS_LOG_SAMPLE_RATE"},
+		},
+		{
+			name:    "file exporter without path",
+			modify:  func(c *AppConfig) { c.Tracing.Exporter, c.Tracing.FilePath = ExporterFile, "" },
+			invalid: []string{"TRACES_FILE_PATH"},
+		},
+		{
+			name:   "sampler names are case insensitive",
+			modify: func(c *AppConfig) { c.Tracing.Sampler = "ParentBased_Always_On" },
+		},
+		{
+			name:    "unknown sampler",
+			modify:  func(c *AppConfig) { c.Tracing.Sampler = "probabilistic" },
+			invalid: []string{"OTEL_TRACES_SAMPLER"},
+		},
+		{
+			name:   "sampler ratio",
+			modify: func(c *AppConfig) { c.Tracing.Sampler, c.Tracing.SamplerArg = "traceidratio", "0.25" },
+		},
+		{
+			name:    "sampler ratio not a number",
+			modify:  func(c *AppConfig) { c.Tracing.SamplerArg = "quarter" },
+			invalid: []string{"OTEL_TRACES_SAMPLER_ARG"},
+		},
+		{
+			name:    "sampler ratio out of range",
+			modify:  func(c *AppConfig) { c.Tracing.SamplerArg = "-0.1" },
+			invalid: []string{"OTEL_TRACES_SAMPLER_ARG"},
+		},
+		{
+			name:    "unknown propagators",
+			modify:  func(c *AppConfig) { c.Tracing.Propagators = List{"tracecontext", "xray", "ot"} },
+			invalid: []string{"OTEL_PROPAGATORS", "OTEL_PROPAGATORS"},
+		},
+		{
+			name:    "memory limit ratio",
+			modify:  func(c *AppConfig) { c.Runtime.MemoryLimitRatio = 2 },
+			invalid: []string{"RUNTIME_MEMORY_LIMIT_RATIO"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.invalid) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a *ValidationError", err)
+			}
+
+			keys := make([]string, len(validationErr.Fields))
+			for i, field := range validationErr.Fields {
+				keys[i] = field.Key
+			}
+			if len(keys) != len(tt.invalid) {
+				t.Fatalf("invalid keys = %v, want %v", keys, tt.invalid)
+			}
+			for i := range keys {
+				if keys[i] != tt.invalid[i] {
+					t.Errorf("invalid keys = %v, want %v", keys, tt.invalid)
+					break
+				}
+			}
+		})
+	}
+}
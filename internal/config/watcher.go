@@ -0,0 +1,230 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+// reloadDelay groups the events of a single file update, such as the
+// several renames of a Kubernetes ConfigMap update, into one reload.
+const reloadDelay = 200 * time.Millisecond
+
+// Change describes a reload that changed at least one setting.
+type Change struct {
+	Old *AppConfig
+	New *AppConfig
+	// Applied lists the keys of changed settings that apply live.
+	Applied []string
+	// Restart lists the keys of settings loaded with a value that only
+	// applies after a restart, including those changed by earlier reloads.
+	// New keeps their running values.
+	Restart []string
+}
+
+type WatcherConfig struct {
+	Sources *Sources
+	// Config is the configuration the service started with.
+	Config *AppConfig
+	Log    *logger.Logger
+}
+
+// Watcher reloads the configuration when its file changes or the process
+// receives SIGHUP, and publishes the changes to its subscribers.
+type Watcher struct {
+	sources *Sources
+	log     *logger.Logger
+	current atomic.Pointer[AppConfig]
+	// loaded is the last configuration loaded, before restoring the running
+	// values of the settings that need a restart.
+	loaded *AppConfig
+
+	mu          sync.Mutex
+	subscribers []func(*Change)
+	status      Status
+}
+
+// Status reports when the configuration was last loaded.
+type Status struct {
+	// LoadedAt is the time of the last successful load, at startup or by a
+	// reload.
+	LoadedAt time.Time `json:"loadedAt"`
+	// Reloads counts the successful reloads.
+	Reloads int `json:"reloads"`
+	// Restart lists the keys loaded with a value that needs a restart to
+	// apply.
+	Restart []string `json:"restartRequired"`
+}
+
+func NewWatcher(cfg *WatcherConfig) *Watcher {
+	w := &Watcher{sources: cfg.Sources, log: cfg.Log.Named("config")}
+	if w.sources == nil {
+		w.sources = &Sources{}
+	}
+	w.current.Store(cfg.Config)
+	w.loaded = cfg.Config
+	w.status = Status{LoadedAt: time.Now(), Restart: []string{}}
+	return w
+}
+
+// Current returns the configuration in effect.
+func (w *Watcher) Current() *AppConfig {
+	return w.current.Load()
+}
+
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := w.status
+	status.Restart = slices.Clone(status.Restart)
+	return status
+}
+
+// Subscribe registers fn to be called after every reload that changed a
+// setting. Subscribers are called in order from the reloading goroutine.
+func (w *Watcher) Subscribe(fn func(*Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload loads and validates the configuration again. On error the current
+// configuration stays in effect. The returned change is nil when nothing
+// changed.
+func (w *Watcher) Reload() (*Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Load(w.sources)
+	if err != nil {
+		return nil, err
+	}
+
+	w.status.LoadedAt = time.Now()
+	w.status.Reloads++
+
+	if equal(next, w.loaded) {
+		return nil, nil
+	}
+	w.loaded, next = next, next.clone()
+
+	old := w.current.Load()
+	change := &Change{Old: old, New: next}
+
+	running := settings(old)
+	for i, setting := range settings(next) {
+		if setting.equal(running[i]) {
+			continue
+		}
+		if setting.Reload {
+			change.Applied = append(change.Applied, setting.Key)
+			continue
+		}
+
+		change.Restart = append(change.Restart, setting.Key)
+		setting.value.Set(running[i].value)
+		next.sources[setting.Key] = old.sources[setting.Key]
+	}
+
+	w.current.Store(next)
+	w.status.Restart = append([]string{}, change.Restart...)
+	for _, subscriber := range w.subscribers {
+		subscriber(change)
+	}
+	return change, nil
+}
+
+func equal(a, b *AppConfig) bool {
+	settingsA, settingsB := settings(a), settings(b)
+	for i := range settingsA {
+		if !settingsA[i].equal(settingsB[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// clone copies the nested structs and sources of c so they can be changed
+// independently. Slice and map settings are never modified and stay shared.
+func (c *AppConfig) clone() *AppConfig {
+	clone := *c
+	web, db, log, accessLog, tracing, runtime := *c.Web, *c.DB, *c.Log, *c.AccessLog, *c.Tracing, *c.Runtime
+	clone.Web, clone.DB, clone.Log, clone.AccessLog, clone.Tracing, clone.Runtime = &web, &db, &log, &accessLog, &tracing, &runtime
+	clone.sources = maps.Clone(c.sources)
+	return &clone
+}
+
+// Run reloads the configuration on SIGHUP and, when a file is configured, on
+// changes to it until ctx is done. The directory of the file is watched
+// rather than the file, since Kubernetes updates a mounted ConfigMap by
+// swapping a symlink instead of writing to the file.
+func (w *Watcher) Run(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if w.sources.File != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to create config file watcher: %w", err)
+		}
+		defer watcher.Close()
+
+		if err := watcher.Add(filepath.Dir(w.sources.File)); err != nil {
+			return fmt.Errorf("failed to watch config file %s: %w", w.sources.File, err)
+		}
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			w.reload("signal")
+		case event := <-events:
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case <-timer.C:
+			w.reload("file")
+		case err := <-errs:
+			w.log.Errorw("Config file watcher failed", "error", err)
+		}
+	}
+}
+
+func (w *Watcher) reload(trigger string) {
+	change, err := w.Reload()
+	if err != nil {
+		w.log.Errorw("Config reload failed, keeping current configuration", "trigger", trigger, "error", err)
+		return
+	}
+	if change == nil {
+		w.log.Infow("Config reloaded without changes", "trigger", trigger)
+		return
+	}
+
+	w.log.Infow("Config reloaded", "trigger", trigger, "applied", change.Applied)
+	if len(change.Restart) > 0 {
+		w.log.Warnw("Config changes require a restart to apply", "keys", change.Restart)
+	}
+}
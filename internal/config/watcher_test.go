@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+func newTestWatcher(t *testing.T, file string) *Watcher {
+	t.Helper()
+
+	sources := &Sources{File: file}
+	cfg, err := Load(sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := logger.New(&logger.Config{Service: "test", Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewWatcher(&WatcherConfig{Sources: sources, Config: cfg, Log: log})
+}
+
+func TestWatcherReload(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		applied []string
+		restart []string
+	}{
+		{
+			name:   "unchanged",
+			before: "log_level: info\n",
+			after:  "log_level: info\n",
+		},
+		{
+			name:    "reloadable setting",
+			before:  "log_level: info\n",
+			after:   "log_level: debug\n",
+			applied: []string{"LOG_LEVEL"},
+		},
+		{
+			name:    "restart required",
+			before:  "db_max_open_conn: 20\n",
+			after:   "db_max_open_conn: 30\n",
+			restart: []string{"DB_MAX_OPEN_CONN"},
+		},
+		{
+			// Headers format with redacted values, which must not hide a
+			// changed credential.
+			name:    "secret header value",
+			before:  "otel_exporter_otlp_headers: authorization=old\n",
+			after:   "otel_exporter_otlp_headers: authorization=new\n",
+			restart: []string{"OTEL_EXPORTER_OTLP_HEADERS"},
+		},
+		{
+			name:    "secret password",
+			before:  "db_password: old\n",
+			after:   "db_password: new\n",
+			restart: []string{"DB_PASSWORD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeFile(t, "config.yaml", tt.before)
+			watcher := newTestWatcher(t, file)
+			checksum := watcher.Current().Checksum()
+
+			if err := os.WriteFile(file, []byte(tt.after), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			change, err := watcher.Reload()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.applied == nil && tt.restart == nil {
+				if change != nil {
+					t.Errorf("got change %+v, want none", change)
+				}
+				return
+			}
+			if change == nil {
+				t.Fatal("change not detected")
+			}
+			if !slices.Equal(change.Applied, tt.applied) {
+				t.Errorf("applied = %v, want %v", change.Applied, tt.applied)
+			}
+			if !slices.Equal(change.Restart, tt.restart) {
+				t.Errorf("restart = %v, want %v", change.Restart, tt.restart)
+			}
+
+			// Settings requiring a restart keep their running value.
+			if tt.restart != nil && watcher.Current().Checksum() != checksum {
+				t.Error("checksum changed although no new value was applied")
+			}
+			if tt.applied != nil && watcher.Current().Checksum() == checksum {
+				t.Error("checksum unchanged after applying a new value")
+			}
+		})
+	}
+}
+
+func TestChecksumCoversSecrets(t *testing.T) {
+	a, b := validConfig(t), validConfig(t)
+	if a.Checksum() != b.Checksum() {
+		t.Fatal("equal configurations have different checksums")
+	}
+
+	b.Tracing.Headers = Headers{"authorization": "rotated"}
+	if a.Checksum() == b.Checksum() {
+		t.Error("checksum unchanged after a header value changed")
+	}
+
+	b = validConfig(t)
+	b.DB.Password = "rotated"
+	if a.Checksum() == b.Checksum() {
+		t.Error("checksum unchanged after the password changed")
+	}
+}
@@ -0,0 +1,346 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/internal/metrics"
+)
+
+const (
+	panelWidth  = 8
+	panelHeight = 8
+	gridWidth   = 24
+
+	routeVariable      = "route"
+	datasourceVariable = "datasource"
+)
+
+var datasource = &DatasourceRef{Type: "prometheus", UID: "${" + datasourceVariable + "}"}
+
+// runtimeMetrics are the Go runtime and process families worth a panel; the
+// collectors expose many more which would only add noise.
+var runtimeMetrics = []string{
+	"go_goroutines",
+	"go_threads",
+	"go_memstats_heap_alloc_bytes",
+	"go_memstats_heap_inuse_bytes",
+	"go_memstats_alloc_bytes_total",
+	"go_gc_duration_seconds",
+	"go_sched_gomaxprocs_threads",
+	"go_gc_gomemlimit_bytes",
+	"process_cpu_seconds_total",
+	"process_resident_memory_bytes",
+	"process_open_fds",
+}
+
+type Config struct {
+	UID         string
+	Title       string
+	Descriptors []metrics.Descriptor
+}
+
+type builder struct {
+	nextID      int
+	y           int
+	x           int
+	panels      []*Panel
+	used        map[string]bool
+	descriptors map[string]metrics.Descriptor
+}
+
+func Generate(cfg *Config) *Dashboard {
+	b := &builder{
+		used:        make(map[string]bool),
+		descriptors: make(map[string]metrics.Descriptor),
+	}
+	for _, descriptor := range cfg.Descriptors {
+		b.descriptors[descriptor.Name] = descriptor
+	}
+
+	b.overview()
+	b.routes()
+	b.section("Database", b.matching(func(name string) bool {
+		return strings.HasPrefix(name, "database_") || strings.HasPrefix(name, "go_sql_")
+	}))
+	b.section("Service level indicators", b.matching(func(name string) bool {
+		return strings.HasPrefix(name, "sli_")
+	}))
+	b.section("Go runtime", b.matching(func(name string) bool {
+		return slices.Contains(runtimeMetrics, name)
+	}))
+	b.section("Application", b.matching(func(name string) bool {
+		return !strings.HasPrefix(name, "go_") &&
+			!strings.HasPrefix(name, "process_") &&
+			!strings.HasPrefix(name, "promhttp_")
+	}))
+
+	return &Dashboard{
+		UID:           cfg.UID,
+		Title:         cfg.Title,
+		Description:   "Generated by cmd/dashboard-gen from the metrics registered by the service.",
+		Tags:          []string{"generated", cfg.UID},
+		Timezone:      "browser",
+		Refresh:       "30s",
+		SchemaVersion: 39,
+		Version:       1,
+		Time:          TimeRange{From: "now-1h", To: "now"},
+		Templating: Templating{List: []*Variable{
+			{
+				Name:  datasourceVariable,
+				Label: "Datasource",
+				Type:  "datasource",
+				Query: "prometheus",
+			},
+			{
+				Name:       routeVariable,
+				Label:      "Route",
+				Type:       "query",
+				Datasource: datasource,
+				Query:      map[string]any{"query": "label_values(http_requests_total, endpoint)", "refId": "routes"},
+				Refresh:    2,
+				Multi:      true,
+				IncludeAll: true,
+				Sort:       1,
+			},
+		}},
+		Panels: b.panels,
+	}
+}
+
+func Marshal(cfg *Config) ([]byte, error) {
+	out, err := json.MarshalIndent(Generate(cfg), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dashboard: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+func (b *builder) overview() {
+	if !b.has("http_requests_total") {
+		return
+	}
+
+	b.row("Overview", "")
+	b.used["http_requests_total"] = true
+	b.timeseries("Request rate", "reqps",
+		target(`sum(rate(http_requests_total[$__rate_interval])) by (status_code)`, "{{status_code}}"),
+	)
+	b.timeseries("Error ratio", "percentunit",
+		target(`sum(rate(http_requests_total{status_code=~"5.."}[$__rate_interval])) / sum(rate(http_requests_total[$__rate_interval]))`, "5xx"),
+	)
+	if b.has("active_requests") {
+		b.used["active_requests"] = true
+		b.timeseries("In-flight requests", "short", target(`sum(active_requests)`, "in flight"))
+	}
+}
+
+// routes emits the RED panels for a single route inside a row that Grafana
+// repeats for every value of the route variable.
+func (b *builder) routes() {
+	if !b.has("http_requests_total") {
+		return
+	}
+
+	selector := fmt.Sprintf(`endpoint=~"$%s"`, routeVariable)
+
+	b.row("Route $"+routeVariable, routeVariable)
+	b.timeseries("Rate", "reqps",
+		target(fmt.Sprintf(`sum(rate(http_requests_total{%s}[$__rate_interval])) by (method)`, selector), "{{method}}"),
+	)
+	b.timeseries("Errors", "percentunit",
+		target(fmt.Sprintf(
+			`sum(rate(http_requests_total{%s,status_code=~"5.."}[$__rate_interval])) / sum(rate(http_requests_total{%s}[$__rate_interval]))`,
+			selector, selector,
+		), "5xx ratio"),
+	)
+
+	if b.has("http_request_duration_seconds") {
+		b.used["http_request_duration_seconds"] = true
+		b.timeseries("Duration", "s", quantileTargets("http_request_duration_seconds", selector, "")...)
+		b.heatmap("Duration heatmap", "http_request_duration_seconds", selector)
+	}
+}
+
+// section adds a row with one panel per descriptor, choosing the query from
+// the metric type.
+func (b *builder) section(title string, descriptors []metrics.Descriptor) {
+	if len(descriptors) == 0 {
+		return
+	}
+
+	b.row(title, "")
+	for _, descriptor := range descriptors {
+		by := strings.Join(descriptor.Labels, ", ")
+		legend := legendFormat(descriptor.Labels)
+		if by == "" {
+			by, legend = "instance", "{{instance}}"
+		}
+
+		switch descriptor.Type {
+		case metrics.TypeCounter:
+			b.timeseries(descriptor.Name, unitFor(descriptor.Name, true),
+				target(fmt.Sprintf(`sum(rate(%s[$__rate_interval])) by (%s)`, descriptor.Name, by), legend),
+			).Description = descriptor.Help
+		case metrics.TypeHistogram:
+			b.timeseries(descriptor.Name, unitFor(descriptor.Name, false),
+				quantileTargets(descriptor.Name, "", by)...,
+			).Description = descriptor.Help
+			b.heatmap(descriptor.Name+" heatmap", descriptor.Name, "")
+		case metrics.TypeSummary:
+			b.timeseries(descriptor.Name, unitFor(descriptor.Name, false),
+				target(fmt.Sprintf(`max(%s{quantile=~"0.5|0.75|1"}) by (quantile)`, descriptor.Name), "q{{quantile}}"),
+			).Description = descriptor.Help
+		default:
+			b.timeseries(descriptor.Name, unitFor(descriptor.Name, false),
+				target(fmt.Sprintf(`sum(%s) by (%s)`, descriptor.Name, by), legend),
+			).Description = descriptor.Help
+		}
+	}
+}
+
+func (b *builder) row(title, repeat string) {
+	if b.x > 0 {
+		b.y += panelHeight
+		b.x = 0
+	}
+
+	b.panels = append(b.panels, &Panel{
+		ID:      b.id(),
+		Type:    "row",
+		Title:   title,
+		Repeat:  repeat,
+		GridPos: GridPos{X: 0, Y: b.y, W: gridWidth, H: 1},
+	})
+	b.y++
+}
+
+func (b *builder) timeseries(title, unit string, targets ...*Target) *Panel {
+	panel := b.panel("timeseries", title, targets...)
+	panel.FieldConfig = &FieldConfig{Defaults: FieldDefaults{Unit: unit}, Overrides: []any{}}
+	panel.Options = map[string]any{
+		"legend":  map[string]any{"displayMode": "list", "placement": "bottom", "showLegend": true},
+		"tooltip": map[string]any{"mode": "multi", "sort": "desc"},
+	}
+	return panel
+}
+
+// heatmap renders the histogram buckets with exemplars enabled so a slow
+// bucket links straight to the trace recorded with the observation.
+func (b *builder) heatmap(title, name, selector string) *Panel {
+	t := target(fmt.Sprintf(`sum(increase(%s[$__rate_interval])) by (le)`, series(name+"_bucket", selector)), "{{le}}")
+	t.Format = "heatmap"
+	t.Exemplar = true
+
+	panel := b.panel("heatmap", title, t)
+	panel.Options = map[string]any{
+		"calculate": false,
+		"yAxis":     map[string]any{"unit": "s"},
+		"color":     map[string]any{"mode": "scheme", "scheme": "Spectral", "steps": 64},
+		"exemplars": map[string]any{"color": "rgba(255,0,255,0.7)"},
+		"cellGap":   1,
+	}
+	return panel
+}
+
+func (b *builder) panel(panelType, title string, targets ...*Target) *Panel {
+	if b.x+panelWidth > gridWidth {
+		b.x = 0
+		b.y += panelHeight
+	}
+
+	for i, t := range targets {
+		t.RefID = string(rune('A' + i))
+		t.Datasource = datasource
+	}
+
+	panel := &Panel{
+		ID:         b.id(),
+		Type:       panelType,
+		Title:      title,
+		Datasource: datasource,
+		Targets:    targets,
+		GridPos:    GridPos{X: b.x, Y: b.y, W: panelWidth, H: panelHeight},
+	}
+
+	b.panels = append(b.panels, panel)
+	b.x += panelWidth
+	return panel
+}
+
+func (b *builder) id() int {
+	b.nextID++
+	return b.nextID
+}
+
+func (b *builder) has(name string) bool {
+	_, ok := b.descriptors[name]
+	return ok
+}
+
+func (b *builder) matching(match func(name string) bool) []metrics.Descriptor {
+	var matched []metrics.Descriptor
+	for _, descriptor := range b.descriptors {
+		if !b.used[descriptor.Name] && match(descriptor.Name) {
+			b.used[descriptor.Name] = true
+			matched = append(matched, descriptor)
+		}
+	}
+
+	slices.SortFunc(matched, func(a, b metrics.Descriptor) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return matched
+}
+
+func target(expr, legend string) *Target {
+	return &Target{Expr: expr, LegendFormat: legend}
+}
+
+func quantileTargets(name, selector, by string) []*Target {
+	group, legend := "le", "p%s"
+	if by != "" && by != "instance" {
+		group, legend = "le, "+by, "p%s "+legendFormat(strings.Split(by, ", "))
+	}
+
+	var targets []*Target
+	for _, quantile := range []string{"50", "95", "99"} {
+		targets = append(targets, target(fmt.Sprintf(
+			`histogram_quantile(0.%s, sum(rate(%s[$__rate_interval])) by (%s))`,
+			quantile, series(name+"_bucket", selector), group,
+		), fmt.Sprintf(legend, quantile)))
+	}
+	return targets
+}
+
+func series(name, selector string) string {
+	if selector == "" {
+		return name
+	}
+	return name + "{" + selector + "}"
+}
+
+func legendFormat(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, "{{"+label+"}}")
+	}
+	return strings.Join(parts, " ")
+}
+
+func unitFor(name string, rate bool) string {
+	switch {
+	case strings.HasSuffix(name, "_seconds"), strings.HasSuffix(name, "_seconds_total") && rate:
+		return "s"
+	case strings.HasSuffix(name, "_bytes"):
+		return "bytes"
+	case strings.HasSuffix(name, "_bytes_total") && rate:
+		return "Bps"
+	case rate:
+		return "ops"
+	default:
+		return "short"
+	}
+}
@@ -0,0 +1,85 @@
+package dashboard
+
+// The types below model the subset of the Grafana dashboard JSON schema used
+// by the generator.
+
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Version       int        `json:"version"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []*Panel   `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []*Variable `json:"list"`
+}
+
+type Variable struct {
+	Name       string         `json:"name"`
+	Label      string         `json:"label,omitempty"`
+	Type       string         `json:"type"`
+	Query      any            `json:"query"`
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+	Refresh    int            `json:"refresh,omitempty"`
+	Multi      bool           `json:"multi"`
+	IncludeAll bool           `json:"includeAll"`
+	Sort       int            `json:"sort,omitempty"`
+}
+
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type Panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	GridPos     GridPos        `json:"gridPos"`
+	Datasource  *DatasourceRef `json:"datasource,omitempty"`
+	Repeat      string         `json:"repeat,omitempty"`
+	Collapsed   bool           `json:"collapsed,omitempty"`
+	Panels      []*Panel       `json:"panels,omitempty"`
+	Targets     []*Target      `json:"targets,omitempty"`
+	FieldConfig *FieldConfig   `json:"fieldConfig,omitempty"`
+	Options     map[string]any `json:"options,omitempty"`
+}
+
+type Target struct {
+	RefID        string         `json:"refId"`
+	Expr         string         `json:"expr"`
+	LegendFormat string         `json:"legendFormat,omitempty"`
+	Format       string         `json:"format,omitempty"`
+	Exemplar     bool           `json:"exemplar,omitempty"`
+	Datasource   *DatasourceRef `json:"datasource,omitempty"`
+}
+
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []any         `json:"overrides"`
+}
+
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
@@ -34,7 +34,7 @@ func Open(cfg *config.DB) (*sql.DB, error) {
 	return db, nil
 }
 
-func StatusCheck(ctx context.Context, db *sql.DB, log *logger.Logger) error {
+func StatusCheck(ctx context.Context, db *sql.DB) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, time.Second*10)
@@ -45,7 +45,7 @@ func StatusCheck(ctx context.Context, db *sql.DB, log *logger.Logger) error {
 		if err := db.PingContext(ctx); err == nil {
 			break
 		} else {
-			log.WithTrace(ctx).Infow("db ping error", "error", err)
+			logger.FromContext(ctx).Infow("db ping error", "error", err)
 		}
 
 		time.Sleep(time.Duration(attempts) * 200 * time.Millisecond)
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+// migrationLockID serializes migrations run concurrently, for example by
+// several jobs, through a Postgres advisory lock.
+const migrationLockID = 7_305_143_721
+
+//go:embed migrations
+var migrations embed.FS
+
+// Migrate applies the embedded migrations not yet recorded in the
+// schema_migrations table, in name order, and returns the applied names.
+func Migrate(ctx context.Context, db *sql.DB) ([]string, error) {
+	log := logger.FromContext(ctx)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Warnw("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+
+	var ran []string
+	for _, name := range names {
+		version := strings.TrimSuffix(path.Base(name), ".sql")
+		if applied[version] {
+			continue
+		}
+
+		if err := applyMigration(ctx, conn, name, version); err != nil {
+			return ran, err
+		}
+		log.Infow("Migration applied", "version", version)
+		ran = append(ran, version)
+	}
+	return ran, nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, name, version string) error {
+	statements, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(statements)); err != nil {
+		return fmt.Errorf("migration %s failed: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", version, err)
+	}
+	return nil
+}
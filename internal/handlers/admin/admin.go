@@ -0,0 +1,44 @@
+package admin_handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/internal/tracing"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+type handler struct {
+	levels *logger.Levels
+	spans  *tracing.SpanStore
+	config *config.Watcher
+}
+
+type Config struct {
+	Log *logger.Logger
+	// Spans is nil when tracing failed to initialize.
+	Spans  *tracing.SpanStore
+	Config *config.Watcher
+}
+
+func New(cfg *Config) *handler {
+	return &handler{
+		levels: cfg.Log.Levels(),
+		spans:  cfg.Spans,
+		config: cfg.Config,
+	}
+}
+
+// wantsHTML serves browsers HTML and everything else JSON, unless the
+// format query parameter asks for one explicitly.
+func wantsHTML(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "html":
+		return true
+	case "json":
+		return false
+	default:
+		return strings.Contains(r.Header.Get("Accept"), "text/html")
+	}
+}
@@ -0,0 +1,26 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+)
+
+type effectiveConfig struct {
+	config.Status
+	Checksum string          `json:"checksum"`
+	Settings []*config.Value `json:"settings"`
+}
+
+// Config returns the configuration in effect with secrets redacted, the
+// source of every value and when it was last reloaded.
+func (h *handler) Config(w http.ResponseWriter, r *http.Request) {
+	cfg := h.config.Current()
+
+	response.RespondSuccess(w, http.StatusOK, "", effectiveConfig{
+		Status:   h.config.Status(),
+		Checksum: cfg.Checksum(),
+		Settings: cfg.Values(),
+	})
+}
@@ -0,0 +1,68 @@
+package admin_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+	"go.uber.org/zap/zapcore"
+)
+
+type logLevels struct {
+	Level     string            `json:"level"`
+	Overrides map[string]string `json:"overrides"`
+}
+
+type setLogLevelRequest struct {
+	// Logger selects a named logger to override; the root level is changed
+	// when it is empty.
+	Logger string `json:"logger"`
+	// Level may be empty together with Logger to remove an override.
+	Level string `json:"level"`
+}
+
+func (h *handler) LogLevel(w http.ResponseWriter, r *http.Request) {
+	response.RespondSuccess(w, http.StatusOK, "", h.logLevels())
+}
+
+// SetLogLevel changes the root level or the level of a named logger, taking
+// effect immediately for every logger derived from the service logger.
+func (h *handler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req setLogLevelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+		response.RespondError(w, r, http.StatusBadRequest, "InvalidRequest", "Request body must be a JSON object", nil)
+		return
+	}
+
+	if req.Logger != "" && req.Level == "" {
+		h.levels.ClearOverride(req.Logger)
+		logger.FromContext(r.Context()).Infow("log level override removed", "logger", req.Logger)
+		response.RespondSuccess(w, http.StatusOK, "Log level override removed", h.logLevels())
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		response.RespondError(w, r, http.StatusBadRequest, "InvalidLogLevel", err.Error(), nil)
+		return
+	}
+
+	if req.Logger == "" {
+		h.levels.SetLevel(level)
+	} else {
+		h.levels.SetOverride(req.Logger, level)
+	}
+
+	// Logged at warn so the change is visible whatever the new level is.
+	logger.FromContext(r.Context()).Warnw("log level changed", "logger", req.Logger, "level", level.String())
+	response.RespondSuccess(w, http.StatusOK, "Log level updated", h.logLevels())
+}
+
+func (h *handler) logLevels() logLevels {
+	levels := logLevels{Level: h.levels.Level().String(), Overrides: make(map[string]string)}
+	for name, level := range h.levels.Overrides() {
+		levels.Overrides[name] = level.String()
+	}
+	return levels
+}
@@ -0,0 +1,14 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/k8sinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+)
+
+// Pod returns the pod and container the service runs in, including its
+// labels, container ID and resources, which /health keeps to itself.
+func (h *handler) Pod(w http.ResponseWriter, r *http.Request) {
+	response.RespondSuccess(w, http.StatusOK, "", k8sinfo.Get())
+}
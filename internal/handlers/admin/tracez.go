@@ -0,0 +1,134 @@
+package admin_handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iamBelugaa/k8s-demo/internal/tracing"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracezTemplates = template.Must(template.New("tracez").Parse(`
+{{define "header"}}<!doctype html>
+<html><head><title>{{.}}</title><style>
+body { font-family: monospace; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+td:first-child, th:first-child { text-align: left; }
+ul.tree { list-style: none; padding-left: 1.5em; border-left: 1px dotted #999; }
+.Error { color: #c00; }
+.attrs { color: #666; font-size: smaller; }
+</style></head><body><h1>{{.}}</h1>{{end}}
+
+{{define "summary"}}{{template "header" "Span names"}}
+<table>
+<tr><th>Name</th><th>Total</th><th>Errors</th>{{range .Buckets}}<th>{{.}}</th>{{end}}</tr>
+{{range $summary := .Summaries}}<tr>
+<td><a href="?name={{.Name}}&kind=recent">{{.Name}}</a></td>
+<td>{{.Total}}</td>
+<td>{{if .Errors}}<a class="Error" href="?name={{.Name}}&kind=errors">{{.Errors}}</a>{{else}}0{{end}}</td>
+{{range $i, $bucket := .Buckets}}<td>{{if .Count}}<a href="?name={{$summary.Name}}&kind=latency&bucket={{$i}}">{{.Count}}</a>{{else}}0{{end}}</td>{{end}}
+</tr>{{end}}
+</table></body></html>{{end}}
+
+{{define "spans"}}{{template "header" .Title}}
+<p><a href="?">All span names</a></p>
+<table>
+<tr><th>Start</th><th>Duration</th><th>Status</th><th>Trace</th></tr>
+{{range .Spans}}<tr>
+<td>{{.Start.Format "15:04:05.000000"}}</td>
+<td>{{.Duration}}</td>
+<td class="{{.Status}}">{{.Status}} {{.StatusMessage}}</td>
+<td><a href="tracez/traces/{{.TraceID}}">{{.TraceID}}</a></td>
+</tr>{{end}}
+</table></body></html>{{end}}
+
+{{define "node"}}<li><span class="{{.Status}}">{{.Name}}</span> {{.Duration}} ({{.Kind}}, {{.Status}}{{if .StatusMessage}}: {{.StatusMessage}}{{end}})
+<div class="attrs">{{range $key, $value := .Attributes}}{{$key}}={{$value}} {{end}}</div>
+{{range .Events}}<div class="attrs">event {{.Name}} at {{.Time.Format "15:04:05.000000"}}</div>{{end}}
+{{if .Children}}<ul class="tree">{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</li>{{end}}
+
+{{define "trace"}}{{template "header" .TraceID}}
+<ul class="tree">{{range .Roots}}{{template "node" .}}{{end}}</ul>
+</body></html>{{end}}
+`))
+
+// Tracez lists the span names seen with their counts per latency bucket.
+// With a name query parameter it lists the stored spans of that name, of the
+// kind recent, errors or latency, the latter selecting a bucket by index.
+func (h *handler) Tracez(w http.ResponseWriter, r *http.Request) {
+	if h.spans == nil {
+		response.RespondError(w, r, http.StatusServiceUnavailable, "TracingUnavailable", "Tracing is not initialized", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	if name := query.Get("name"); name != "" {
+		kind := query.Get("kind")
+		if kind == "" {
+			kind = tracing.SpansRecent
+		}
+
+		bucket, _ := strconv.Atoi(query.Get("bucket"))
+		spans := h.spans.Spans(name, kind, bucket)
+
+		if wantsHTML(r) {
+			h.render(w, r, "spans", map[string]any{"Title": name + " (" + kind + ")", "Spans": spans})
+			return
+		}
+		response.RespondSuccess(w, http.StatusOK, "", spans)
+		return
+	}
+
+	summaries := h.spans.Summaries()
+	if wantsHTML(r) {
+		buckets := make([]string, len(tracing.LatencyBuckets))
+		for i := range buckets {
+			if i+1 < len(tracing.LatencyBuckets) {
+				buckets[i] = "<" + tracing.LatencyBuckets[i+1].String()
+			} else {
+				buckets[i] = ">=" + tracing.LatencyBuckets[i].String()
+			}
+		}
+		h.render(w, r, "summary", map[string]any{"Summaries": summaries, "Buckets": buckets})
+		return
+	}
+	response.RespondSuccess(w, http.StatusOK, "", summaries)
+}
+
+// Trace renders the stored spans of a trace as a tree.
+func (h *handler) Trace(w http.ResponseWriter, r *http.Request) {
+	if h.spans == nil {
+		response.RespondError(w, r, http.StatusServiceUnavailable, "TracingUnavailable", "Tracing is not initialized", nil)
+		return
+	}
+
+	traceID, err := trace.TraceIDFromHex(chi.URLParam(r, "traceID"))
+	if err != nil {
+		response.RespondError(w, r, http.StatusBadRequest, "InvalidTraceID", "Trace ID must be 32 hex characters", nil)
+		return
+	}
+
+	roots := h.spans.Trace(traceID)
+	if len(roots) == 0 {
+		response.RespondError(w, r, http.StatusNotFound, "TraceNotFound", "Trace is not in the span buffer", nil)
+		return
+	}
+
+	if wantsHTML(r) {
+		h.render(w, r, "trace", map[string]any{"TraceID": traceID.String(), "Roots": roots})
+		return
+	}
+	response.RespondSuccess(w, http.StatusOK, "", roots)
+}
+
+func (h *handler) render(w http.ResponseWriter, r *http.Request, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tracezTemplates.ExecuteTemplate(w, name, data); err != nil {
+		logger.FromContext(r.Context()).Warnw("failed to render tracez page", "template", name, "error", err)
+	}
+}
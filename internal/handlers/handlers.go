@@ -5,11 +5,16 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	admin_handlers "github.com/iamBelugaa/k8s-demo/internal/handlers/admin"
 	health_handlers "github.com/iamBelugaa/k8s-demo/internal/handlers/health"
+	version_handlers "github.com/iamBelugaa/k8s-demo/internal/handlers/version"
 	"github.com/iamBelugaa/k8s-demo/internal/metrics"
 	"github.com/iamBelugaa/k8s-demo/internal/middlewares"
+	"github.com/iamBelugaa/k8s-demo/internal/tracing"
 	"github.com/iamBelugaa/k8s-demo/pkg/logger"
 )
 
@@ -18,27 +23,79 @@ type Config struct {
 	Version string
 	DB      *sql.DB
 	Router  *chi.Mux
-	Log     *logger.Logger
-	Metrics *metrics.Metrics
+	// AdminRouter serves the admin endpoints on a listener kept off the
+	// Service and Ingress.
+	AdminRouter *chi.Mux
+	Log         *logger.Logger
+	Metrics     *metrics.Metrics
+	SLOs        config.SLOs
+	// AccessLog and Web return the settings in effect, which can change on
+	// reload.
+	AccessLog func() *config.AccessLog
+	Web       func() *config.Web
+	Config    *config.Watcher
+	Tracing   func() tracing.Health
+	Spans     *tracing.SpanStore
 }
 
 func SetupRoutes(cfg *Config) {
 	cfg.Router.Use(middleware.RequestID)
 	cfg.Router.Use(middleware.RealIP)
-	cfg.Router.Use(middleware.Logger)
 	cfg.Router.Use(middleware.Recoverer)
+	cfg.Router.Use(middlewares.DeadlineMiddleware(cfg.Web))
 
-	cfg.Router.Use(middlewares.MetricsMiddleware(cfg.Metrics))
+	// Tracing wraps access logging and metrics so both can carry the trace
+	// ID, the latter as an exemplar.
 	cfg.Router.Use(middlewares.TracingMiddleware(cfg.Service))
+	cfg.Router.Use(middlewares.AccessLogMiddleware(cfg.Log, cfg.AccessLog))
+	cfg.Router.Use(middlewares.MetricsMiddleware(cfg.Metrics, cfg.SLOs))
+	cfg.Router.Use(middlewares.CorrelationMiddleware)
 
 	healthHandlers := health_handlers.New(&health_handlers.Config{
 		Service: cfg.Service,
 		Version: cfg.Version,
 		DB:      cfg.DB,
-		Log:     cfg.Log,
 		Metrics: cfg.Metrics,
+		Tracing: cfg.Tracing,
 	})
 
-	cfg.Router.Handle("/metrics", promhttp.Handler())
-	cfg.Router.Get("/health", healthHandlers.HealthCheck)
+	// Middlewares of a group run once the route is matched, so the request
+	// logger can carry the route pattern.
+	cfg.Router.Group(func(r chi.Router) {
+		r.Use(middlewares.RequestLoggerMiddleware(cfg.Log))
+
+		r.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}))
+		r.Get("/health", healthHandlers.HealthCheck)
+		r.Get("/version", version_handlers.Version)
+	})
+}
+
+// SetupAdminRoutes registers the endpoints exposing span attributes, the
+// effective configuration and pod details, and changing the log level, on the
+// admin router.
+// Requests to them are neither traced nor metered.
+func SetupAdminRoutes(cfg *Config) {
+	cfg.AdminRouter.Use(middleware.RequestID)
+	cfg.AdminRouter.Use(middleware.Recoverer)
+
+	adminHandlers := admin_handlers.New(&admin_handlers.Config{
+		Log:    cfg.Log,
+		Spans:  cfg.Spans,
+		Config: cfg.Config,
+	})
+
+	cfg.AdminRouter.Group(func(r chi.Router) {
+		r.Use(middlewares.RequestLoggerMiddleware(cfg.Log))
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Get("/tracez", adminHandlers.Tracez)
+			r.Get("/tracez/traces/{traceID}", adminHandlers.Trace)
+			r.Get("/log/level", adminHandlers.LogLevel)
+			r.Put("/log/level", adminHandlers.SetLogLevel)
+			r.Get("/config", adminHandlers.Config)
+			r.Get("/pod", adminHandlers.Pod)
+		})
+	})
 }
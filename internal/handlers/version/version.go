@@ -0,0 +1,26 @@
+package version_handlers
+
+import (
+	"net/http"
+
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/response"
+	"github.com/iamBelugaa/k8s-demo/pkg/runtimetune"
+)
+
+type version struct {
+	*buildinfo.Info
+	// Runtime is how GOMAXPROCS and GOMEMLIMIT were set from the container
+	// limits.
+	Runtime *runtimetune.Decision `json:"runtime,omitempty"`
+}
+
+// Version returns the build information of the running binary and its
+// runtime tuning. Module dependencies are left out, as the endpoint is public;
+// the version command prints them.
+func Version(w http.ResponseWriter, r *http.Request) {
+	info := *buildinfo.Get()
+	info.Deps = nil
+
+	response.RespondSuccess(w, http.StatusOK, "", version{Info: &info, Runtime: runtimetune.Applied()})
+}
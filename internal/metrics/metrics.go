@@ -1,29 +1,62 @@
 package metrics
 
 import (
+	"database/sql"
+	"slices"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+	TypeSummary   = "summary"
+	TypeUntyped   = "untyped"
 )
 
+// Descriptor describes a registered metric family so tooling such as the
+// dashboard generator can introspect what the service exposes.
+type Descriptor struct {
+	Name   string
+	Help   string
+	Type   string
+	Labels []string
+}
+
 type Metrics struct {
 	DatabaseConnections   prometheus.Gauge
 	ActiveRequests        prometheus.Gauge
 	HTTPRequestsTotal     *prometheus.CounterVec
 	HTTPRequestDuration   *prometheus.HistogramVec
 	DatabaseQueryDuration *prometheus.HistogramVec
+	SLIRequestsTotal      *prometheus.CounterVec
+	SLIErrorsTotal        *prometheus.CounterVec
+	SLILatencyGoodTotal   *prometheus.CounterVec
+	descriptors           []Descriptor
+	registerer            prometheus.Registerer
 }
 
-func New() *Metrics {
-	return &Metrics{
+// New registers the application metrics with the default registry. The
+// constant labels, such as the pod name, are added to every metric
+// registered through Metrics.
+func New(constLabels prometheus.Labels) *Metrics {
+	registerer := prometheus.WrapRegistererWith(constLabels, prometheus.DefaultRegisterer)
+	r := &registry{factory: promauto.With(registerer)}
+
+	m := &Metrics{
 		// HTTP request metrics.
-		HTTPRequestsTotal: promauto.NewCounterVec(
+		HTTPRequestsTotal: r.counterVec(
 			prometheus.CounterOpts{
 				Name: "http_requests_total",
 				Help: "Total number of HTTP requests",
 			},
 			[]string{"method", "endpoint", "status_code"},
 		),
-		HTTPRequestDuration: promauto.NewHistogramVec(
+		HTTPRequestDuration: r.histogramVec(
 			prometheus.HistogramOpts{
 				Name:    "http_request_duration_seconds",
 				Help:    "Duration of HTTP requests in seconds",
@@ -32,14 +65,37 @@ func New() *Metrics {
 			[]string{"method", "endpoint"},
 		),
 
+		// SLI metrics, only recorded for routes with a declared SLO.
+		SLIRequestsTotal: r.counterVec(
+			prometheus.CounterOpts{
+				Name: "sli_requests_total",
+				Help: "Total number of requests counted towards a route SLO",
+			},
+			[]string{"route"},
+		),
+		SLIErrorsTotal: r.counterVec(
+			prometheus.CounterOpts{
+				Name: "sli_errors_total",
+				Help: "Number of requests that failed a route availability SLO",
+			},
+			[]string{"route"},
+		),
+		SLILatencyGoodTotal: r.counterVec(
+			prometheus.CounterOpts{
+				Name: "sli_latency_good_total",
+				Help: "Number of requests completed within a route latency SLO threshold",
+			},
+			[]string{"route"},
+		),
+
 		// Database metrics.
-		DatabaseConnections: promauto.NewGauge(
+		DatabaseConnections: r.gauge(
 			prometheus.GaugeOpts{
 				Name: "database_connections_active",
 				Help: "Number of active database connections",
 			},
 		),
-		DatabaseQueryDuration: promauto.NewHistogramVec(
+		DatabaseQueryDuration: r.histogramVec(
 			prometheus.HistogramOpts{
 				Name:    "database_query_duration_seconds",
 				Help:    "Duration of database queries in seconds",
@@ -49,20 +105,130 @@ func New() *Metrics {
 		),
 
 		// Application metrics.
-		ActiveRequests: promauto.NewGauge(
+		ActiveRequests: r.gauge(
 			prometheus.GaugeOpts{
 				Name: "active_requests",
 				Help: "Number of requests currently being processed",
 			},
 		),
 	}
+
+	m.descriptors = r.descriptors
+	m.registerer = registerer
+	return m
 }
 
-func (m *Metrics) RecordHTTPRequest(method, endpoint, statusCode string, duration float64) {
+// Descriptors returns the metrics registered by New followed by any other
+// collector families exposed by gatherer, such as the Go runtime and process
+// collectors of the default registry.
+func (m *Metrics) Descriptors(gatherer prometheus.Gatherer) ([]Descriptor, error) {
+	descriptors := slices.Clone(m.descriptors)
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, family := range families {
+		known := slices.ContainsFunc(descriptors, func(d Descriptor) bool {
+			return d.Name == family.GetName()
+		})
+		if known {
+			continue
+		}
+
+		var labels []string
+		if len(family.GetMetric()) > 0 {
+			for _, pair := range family.GetMetric()[0].GetLabel() {
+				labels = append(labels, pair.GetName())
+			}
+		}
+
+		descriptors = append(descriptors, Descriptor{
+			Name:   family.GetName(),
+			Help:   family.GetHelp(),
+			Type:   familyType(family.GetType()),
+			Labels: labels,
+		})
+	}
+
+	return descriptors, nil
+}
+
+// Register exposes an additional collector, such as the tracing exporter
+// health, alongside the application metrics.
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registerer.Register(collector)
+}
+
+// RegisterDBStats exposes the connection pool statistics of db as go_sql_*
+// metrics labelled with dbName.
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) error {
+	return m.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// RecordHTTPRequest records a request, attaching traceID as an exemplar to the
+// latency observation when the request was traced.
+func (m *Metrics) RecordHTTPRequest(method, endpoint, statusCode string, duration float64, traceID string) {
 	m.HTTPRequestsTotal.WithLabelValues(method, endpoint, statusCode).Inc()
-	m.HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(duration)
+
+	observer := m.HTTPRequestDuration.WithLabelValues(method, endpoint)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && traceID != "" {
+		exemplarObserver.ObserveWithExemplar(duration, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(duration)
+}
+
+func (m *Metrics) RecordSLI(route string, failed, withinLatency bool) {
+	m.SLIRequestsTotal.WithLabelValues(route).Inc()
+	if failed {
+		m.SLIErrorsTotal.WithLabelValues(route).Inc()
+	}
+	if withinLatency {
+		m.SLILatencyGoodTotal.WithLabelValues(route).Inc()
+	}
 }
 
 func (m *Metrics) RecordDatabaseQuery(queryType string, duration float64) {
 	m.DatabaseQueryDuration.WithLabelValues(queryType).Observe(duration)
 }
+
+type registry struct {
+	factory     promauto.Factory
+	descriptors []Descriptor
+}
+
+func (r *registry) counterVec(opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	r.describe(opts.Name, opts.Help, TypeCounter, labels)
+	return r.factory.NewCounterVec(opts, labels)
+}
+
+func (r *registry) histogramVec(opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	r.describe(opts.Name, opts.Help, TypeHistogram, labels)
+	return r.factory.NewHistogramVec(opts, labels)
+}
+
+func (r *registry) gauge(opts prometheus.GaugeOpts) prometheus.Gauge {
+	r.describe(opts.Name, opts.Help, TypeGauge, nil)
+	return r.factory.NewGauge(opts)
+}
+
+func (r *registry) describe(name, help, metricType string, labels []string) {
+	r.descriptors = append(r.descriptors, Descriptor{Name: name, Help: help, Type: metricType, Labels: labels})
+}
+
+func familyType(metricType dto.MetricType) string {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return TypeCounter
+	case dto.MetricType_GAUGE:
+		return TypeGauge
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		return TypeHistogram
+	case dto.MetricType_SUMMARY:
+		return TypeSummary
+	default:
+		return TypeUntyped
+	}
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLogMiddleware writes one structured entry per request to the
+// "http.access" logger. It must run inside TracingMiddleware to pick up the
+// trace, and inside middleware.Recoverer so panics are logged as 500s. The
+// settings are read on every request so reloads apply immediately.
+func AccessLogMiddleware(log *logger.Logger, settings func() *config.AccessLog) func(next http.Handler) http.Handler {
+	log = log.Named("http.access")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := settings()
+			if excluded(cfg.ExcludePaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			defer func() {
+				if rec := recover(); rec != nil {
+					wrapped.statusCode = http.StatusInternalServerError
+					logAccess(log, r, wrapped, time.Since(start))
+					panic(rec)
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.statusCode >= http.StatusInternalServerError || rand.Float64() < cfg.SampleRate {
+				logAccess(log, r, wrapped, time.Since(start))
+			}
+		})
+	}
+}
+
+func logAccess(log *logger.Logger, r *http.Request, rw *responseWriter, elapsed time.Duration) {
+	clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		clientIP = r.RemoteAddr
+	}
+
+	fields := []any{
+		"method", r.Method,
+		"route", routePattern(r),
+		"path", r.URL.Path,
+		"status", rw.statusCode,
+		"bytes", rw.bytes,
+		"duration_ms", float64(elapsed) / float64(time.Millisecond),
+		"request_id", middleware.GetReqID(r.Context()),
+		"client_ip", clientIP,
+		"user_agent", r.UserAgent(),
+	}
+
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+		fields = append(fields,
+			"trace_id", spanContext.TraceID().String(),
+			"span_id", spanContext.SpanID().String(),
+		)
+	}
+
+	if rw.statusCode >= http.StatusInternalServerError {
+		log.Warnw("request completed", fields...)
+		return
+	}
+	log.Infow("request completed", fields...)
+}
+
+func excluded(paths []string, path string) bool {
+	for _, pattern := range paths {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceIDHeader   = "X-Trace-Id"
+	RequestIDHeader = "X-Request-Id"
+)
+
+// CorrelationMiddleware returns the request ID and the trace of the server
+// span as response headers. The trace ID is only returned for sampled traces,
+// the others never reaching Jaeger. It must run after the request ID and
+// tracing middlewares, and sets the headers before the handler writes the
+// response.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestID := middleware.GetReqID(r.Context()); requestID != "" {
+			w.Header().Set(RequestIDHeader, requestID)
+		}
+
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			if spanContext.IsSampled() {
+				w.Header().Set(TraceIDHeader, spanContext.TraceID().String())
+			}
+			propagation.TraceContext{}.Inject(r.Context(), propagation.HeaderCarrier(w.Header()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
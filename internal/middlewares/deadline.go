@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+)
+
+// DeadlineMiddleware applies the read and write timeouts in effect to each
+// request, so reloaded timeouts apply without restarting the server. The
+// http.Server timeouts still bound reading the request headers.
+func DeadlineMiddleware(settings func() *config.Web) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			web := settings()
+			now := time.Now()
+			controller := http.NewResponseController(w)
+
+			// Writers that cannot set deadlines, such as test recorders,
+			// keep the server timeouts.
+			_ = controller.SetReadDeadline(now.Add(web.ReadTimeout))
+			_ = controller.SetWriteDeadline(now.Add(web.WriteTimeout))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
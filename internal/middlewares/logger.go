@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+)
+
+// RequestLoggerMiddleware stores a request scoped logger carrying the
+// request ID and route in the context, retrieved with logger.FromContext.
+// The route pattern is only known once routing is done, so it must be
+// installed with chi's With or Group rather than on the router itself.
+func RequestLoggerMiddleware(log *logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestLog := log.With(
+				"request_id", middleware.GetReqID(r.Context()),
+				"route", routePattern(r),
+			)
+
+			ctx := logger.NewContext(r.Context(), requestLog)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
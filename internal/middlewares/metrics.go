@@ -5,10 +5,15 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/iamBelugaa/k8s-demo/internal/config"
 	"github.com/iamBelugaa/k8s-demo/internal/metrics"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func MetricsMiddleware(metrics *metrics.Metrics) func(next http.Handler) http.Handler {
+// MetricsMiddleware records the request count, duration and, for routes with
+// an SLO, the SLIs. It runs inside middleware.Recoverer, so panics are
+// recorded as 500s before they are re-panicked.
+func MetricsMiddleware(metrics *metrics.Metrics, slos config.SLOs) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -17,11 +22,34 @@ func MetricsMiddleware(metrics *metrics.Metrics) func(next http.Handler) http.Ha
 			defer metrics.ActiveRequests.Dec()
 
 			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			defer func() {
+				if rec := recover(); rec != nil {
+					wrapped.statusCode = http.StatusInternalServerError
+					recordRequest(metrics, slos, r, wrapped.statusCode, time.Since(start))
+					panic(rec)
+				}
+			}()
+
 			next.ServeHTTP(wrapped, r)
 
-			statusCode := strconv.Itoa(wrapped.statusCode)
-			duration := float64(time.Since(start).Milliseconds())
-			metrics.RecordHTTPRequest(r.Method, r.URL.Path, statusCode, duration)
+			recordRequest(metrics, slos, r, wrapped.statusCode, time.Since(start))
 		})
 	}
 }
+
+func recordRequest(metrics *metrics.Metrics, slos config.SLOs, r *http.Request, status int, elapsed time.Duration) {
+	route := routePattern(r)
+
+	var traceID string
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsSampled() {
+		traceID = spanContext.TraceID().String()
+	}
+	metrics.RecordHTTPRequest(r.Method, route, strconv.Itoa(status), elapsed.Seconds(), traceID)
+
+	if slo, ok := slos.Lookup(route); ok {
+		failed := status >= http.StatusInternalServerError
+		withinLatency := slo.LatencyThreshold > 0 && elapsed <= slo.LatencyThreshold
+		metrics.RecordSLI(route, failed, withinLatency)
+	}
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/internal/metrics"
+)
+
+// testMetrics is shared by the tests as New registers with the default
+// registry, which rejects a second registration.
+var testMetrics = metrics.New(nil)
+
+func newMetricsRouter(t *testing.T, slos string) *chi.Mux {
+	t.Helper()
+
+	var objectives config.SLOs
+	if err := objectives.UnmarshalText([]byte(slos)); err != nil {
+		t.Fatal(err)
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+	router.Use(MetricsMiddleware(testMetrics, objectives))
+	return router
+}
+
+func TestMetricsMiddlewareCountsPanics(t *testing.T) {
+	router := newMetricsRouter(t, "/panic|99.9")
+	router.Get("/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	requests := testMetrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/panic", "500")
+	errors := testMetrics.SLIErrorsTotal.WithLabelValues("/panic")
+	requestsBefore, errorsBefore := testutil.ToFloat64(requests), testutil.ToFloat64(errors)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if got := testutil.ToFloat64(requests) - requestsBefore; got != 1 {
+		t.Errorf("http_requests_total increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(errors) - errorsBefore; got != 1 {
+		t.Errorf("sli_errors_total increased by %v, want 1", got)
+	}
+}
+
+func TestMetricsMiddlewareUnmatchedRoute(t *testing.T) {
+	router := newMetricsRouter(t, "")
+	router.Get("/users/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	tests := []struct {
+		path  string
+		route string
+		code  string
+	}{
+		{path: "/users/42", route: "/users/{id}", code: "200"},
+		{path: "/wp-login.php", route: unmatchedRoute, code: "404"},
+		{path: "/.env", route: unmatchedRoute, code: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			counter := testMetrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, tt.route, tt.code)
+			before := testutil.ToFloat64(counter)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("requests for endpoint %q increased by %v, want 1", tt.route, got)
+			}
+		})
+	}
+}
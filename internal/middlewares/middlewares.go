@@ -2,10 +2,13 @@ package middlewares
 
 import (
 	"net/http"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type responseWriter struct {
 	statusCode int
+	bytes      int64
 	http.ResponseWriter
 }
 
@@ -13,3 +16,37 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// unmatchedRoute stands in for the route of requests the router did not
+// match, keeping arbitrary paths out of metric labels.
+const unmatchedRoute = "unmatched"
+
+// routePattern returns the chi route pattern matched for the request, or
+// unmatchedRoute when the router did not match a route. It is only complete
+// once the request has been routed.
+func routePattern(r *http.Request) string {
+	if pattern, ok := matchedRoute(r); ok {
+		return pattern
+	}
+	return unmatchedRoute
+}
+
+func matchedRoute(r *http.Request) (string, bool) {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern, true
+		}
+	}
+	return "", false
+}
@@ -1,32 +1,115 @@
 package middlewares
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/iamBelugaa/k8s-demo/internal/tracing"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// TracingMiddleware starts a server span per request following the HTTP
+// semantic conventions. It must run after middleware.RealIP so that
+// client.address is the real client, and inside middleware.Recoverer so
+// panics are recorded before they are recovered.
 func TracingMiddleware(serviceName string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 			tracer := tracing.GetTracer(serviceName)
 
-			ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path)
+			// Attributes passed at start are visible to the sampler, which
+			// applies route overrides using url.path.
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(requestAttributes(r)...),
+			)
 			defer span.End()
 
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			r = r.WithContext(ctx)
+
+			// The span is ended here, before re-panicking, so the exception
+			// event is recorded once with its stack trace and the outer
+			// deferred End becomes a no-op.
+			defer func() {
+				if rec := recover(); rec != nil {
+					setRoute(span, r)
+					span.RecordError(fmt.Errorf("panic: %v", rec), trace.WithStackTrace(true))
+					span.SetAttributes(semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+					span.SetStatus(codes.Error, "panic recovered")
+					span.End()
+					panic(rec)
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r)
+
+			setRoute(span, r)
 			span.SetAttributes(
-				attribute.String("http.method", r.Method),
-				attribute.String("http.url", r.URL.String()),
-				attribute.String("http.route", r.URL.Path),
-				attribute.String("http.user_agent", r.UserAgent()),
-				attribute.String("http.remote_addr", r.RemoteAddr),
+				semconv.HTTPResponseStatusCode(wrapped.statusCode),
+				semconv.HTTPResponseBodySize(int(wrapped.bytes)),
 			)
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			// Per the conventions only 5xx responses are errors for server
+			// spans; 4xx are the client's fault and leave the status unset.
+			if wrapped.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+			}
 		})
 	}
 }
+
+func setRoute(span trace.Span, r *http.Request) {
+	if route, ok := matchedRoute(r); ok {
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+}
+
+func requestAttributes(r *http.Request) []attribute.KeyValue {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	// The query is left out as it regularly carries tokens.
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.URLPath(r.URL.Path),
+		semconv.URLScheme(scheme),
+		semconv.UserAgentOriginal(r.UserAgent()),
+		semconv.NetworkProtocolVersion(fmt.Sprintf("%d.%d", r.ProtoMajor, r.ProtoMinor)),
+	}
+
+	if r.ContentLength > 0 {
+		attrs = append(attrs, semconv.HTTPRequestBodySize(int(r.ContentLength)))
+	}
+
+	if host, port, err := net.SplitHostPort(r.Host); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	} else if r.Host != "" {
+		attrs = append(attrs, semconv.ServerAddress(r.Host))
+	}
+
+	// middleware.RealIP rewrites RemoteAddr to the bare client IP taken from
+	// X-Forwarded-For or X-Real-IP, so it may or may not carry a port.
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		attrs = append(attrs, semconv.ClientAddress(host))
+	} else if r.RemoteAddr != "" {
+		attrs = append(attrs, semconv.ClientAddress(r.RemoteAddr))
+	}
+
+	return attrs
+}
@@ -12,81 +12,210 @@ import (
 	"github.com/iamBelugaa/k8s-demo/internal/handlers"
 	"github.com/iamBelugaa/k8s-demo/internal/metrics"
 	"github.com/iamBelugaa/k8s-demo/internal/tracing"
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/k8sinfo"
 	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/iamBelugaa/k8s-demo/pkg/runtimetune"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
 )
 
 type Server struct {
-	db         *sql.DB
-	httpServer *http.Server
-	logger     *logger.Logger
-	metrics    *metrics.Metrics
-	config     *config.AppConfig
-	shutdown   func(context.Context) error
+	db          *sql.DB
+	httpServer  *http.Server
+	adminServer *http.Server
+	logger      *logger.Logger
+	metrics     *metrics.Metrics
+	config      *config.AppConfig
+	watcher     *config.Watcher
+	shutdown    func(context.Context) error
 }
 
-func New(ctx context.Context, cfg *config.AppConfig, log *logger.Logger) (*Server, error) {
-	shutdown, err := tracing.New(
+func New(ctx context.Context, watcher *config.Watcher, log *logger.Logger) (*Server, error) {
+	cfg := watcher.Current()
+
+	appMetrics, err := NewMetrics(log, k8sinfo.Get().ConstLabels())
+	if err != nil {
+		return nil, err
+	}
+	log.Infow("Metrics initialized successfully")
+
+	var (
+		shutdown      func(context.Context) error
+		tracingHealth func() tracing.Health
+		spans         *tracing.SpanStore
+	)
+
+	provider, err := tracing.New(
 		&tracing.TracingConfig{
 			ServiceName:    cfg.ServiceName,
 			ServiceVersion: cfg.ServiceVersion,
 			Environment:    cfg.Environment,
-			JaegerEndpoint: cfg.JaegerEndpoint,
+			Exporter: &tracing.ExporterConfig{
+				Name:        cfg.Tracing.Exporter,
+				Protocol:    cfg.Tracing.Protocol,
+				Endpoint:    cfg.Tracing.Endpoint,
+				Headers:     cfg.Tracing.Headers,
+				Compression: cfg.Tracing.Compression,
+				Insecure:    cfg.Tracing.Insecure,
+				Certificate: cfg.Tracing.Certificate,
+				ClientCert:  cfg.Tracing.ClientCert,
+				ClientKey:   cfg.Tracing.ClientKey,
+				FilePath:    cfg.Tracing.FilePath,
+			},
+			Propagators: cfg.Tracing.Propagators,
+			Sampler: &tracing.SamplerConfig{
+				Name:          cfg.Tracing.Sampler,
+				Arg:           cfg.Tracing.SamplerArg,
+				Environment:   cfg.Environment,
+				SampleErrors:  cfg.Tracing.SampleErrors,
+				RouteSampling: cfg.Tracing.RouteSampling,
+			},
+			Log: log,
 		},
 	)
 	if err != nil {
-		log.Warnw("Failed to initialize tracing", "error", err)
+		if cfg.Tracing.Required {
+			return nil, fmt.Errorf("failed to initialize tracing: %w", err)
+		}
+
+		log.Errorw("Failed to initialize tracing, continuing without trace export",
+			"error", err,
+			"exporter", cfg.Tracing.Exporter,
+		)
+
 		shutdown = func(context.Context) error { return nil }
+		tracingHealth = func() tracing.Health { return tracing.UnavailableHealth(cfg.Tracing.Exporter, err) }
 	} else {
+		if err := appMetrics.Register(provider.Collector()); err != nil {
+			return nil, fmt.Errorf("failed to register tracing metrics: %w", err)
+		}
+
+		shutdown = provider.Shutdown
+		tracingHealth = provider.Health
+		spans = provider.Spans()
 		log.Infow("Tracing initialized successfully",
 			"service", cfg.ServiceName,
 			"version", cfg.ServiceVersion,
 			"environment", cfg.Environment,
-			"endpoint", cfg.JaegerEndpoint,
+			"exporter", provider.Exporter(),
+			"protocol", cfg.Tracing.Protocol,
+			"endpoint", cfg.Tracing.Endpoint,
+			"sampler", provider.SamplerDescription(),
+			"propagators", cfg.Tracing.Propagators.String(),
 		)
 	}
 
-	appMetrics := metrics.New()
-	log.Infow("Metrics initialized successfully")
-
 	db, err := database.Open(cfg.DB)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	if err := appMetrics.RegisterDBStats(db, cfg.DB.Name); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
+
 	dbCtx, dbSpan := tracing.StartSpan(ctx, cfg.ServiceName, "startup_check")
-	if err := database.StatusCheck(dbCtx, db, log); err != nil {
+	if err := database.StatusCheck(logger.NewContext(dbCtx, log), db); err != nil {
 		dbSpan.End()
 		return nil, fmt.Errorf("database status check failed: %w", err)
 	}
 	dbSpan.End()
 	log.Infow("Database connection verified successfully")
 
-	router := chi.NewRouter()
-	handlers.SetupRoutes(&handlers.Config{
-		DB:      db,
-		Log:     log,
-		Router:  router,
-		Metrics: appMetrics,
-		Service: cfg.ServiceName,
-		Version: cfg.ServiceVersion,
-	})
+	routes := &handlers.Config{
+		DB:          db,
+		Log:         log,
+		Router:      chi.NewRouter(),
+		AdminRouter: chi.NewRouter(),
+		Metrics:     appMetrics,
+		SLOs:        cfg.SLOs,
+		AccessLog:   func() *config.AccessLog { return watcher.Current().AccessLog },
+		Web:         func() *config.Web { return watcher.Current().Web },
+		Config:      watcher,
+		Service:     cfg.ServiceName,
+		Version:     cfg.ServiceVersion,
+		Tracing:     tracingHealth,
+		Spans:       spans,
+	}
+	handlers.SetupRoutes(routes)
 
 	server := &http.Server{
-		Handler:      router,
+		Handler:      routes.Router,
 		Addr:         cfg.Web.APIHost,
 		ReadTimeout:  cfg.Web.ReadTimeout,
 		IdleTimeout:  cfg.Web.IdleTimeout,
 		WriteTimeout: cfg.Web.WriteTimeout,
 	}
 
-	return &Server{
-		httpServer: server,
-		db:         db,
-		logger:     log,
-		config:     cfg,
-		metrics:    appMetrics,
-		shutdown:   shutdown,
-	}, nil
+	// The admin endpoints get their own listener, bound to loopback by
+	// default, so they are never reachable through the Service or Ingress.
+	var adminServer *http.Server
+	if cfg.Web.AdminHost != "" {
+		handlers.SetupAdminRoutes(routes)
+		adminServer = &http.Server{
+			Handler:      routes.AdminRouter,
+			Addr:         cfg.Web.AdminHost,
+			ReadTimeout:  cfg.Web.ReadTimeout,
+			IdleTimeout:  cfg.Web.IdleTimeout,
+			WriteTimeout: cfg.Web.WriteTimeout,
+		}
+	}
+
+	s := &Server{
+		httpServer:  server,
+		adminServer: adminServer,
+		db:          db,
+		logger:      log,
+		config:      cfg,
+		watcher:     watcher,
+		metrics:     appMetrics,
+		shutdown:    shutdown,
+	}
+	watcher.Subscribe(s.applyConfig)
+
+	return s, nil
+}
+
+// applyConfig applies reloaded logging settings. Access logging and request
+// timeouts read the watcher on every request instead.
+// NewMetrics registers the application metrics along with the collectors of
+// the logger, the build and the runtime tuning. The tracing and database
+// collectors are registered once those are set up. The dashboard generator
+// calls it too, so the dashboard covers the same metrics.
+func NewMetrics(log *logger.Logger, constLabels prometheus.Labels) (*metrics.Metrics, error) {
+	appMetrics := metrics.New(constLabels)
+	if err := appMetrics.Register(log.Collector()); err != nil {
+		return nil, fmt.Errorf("failed to register logging metrics: %w", err)
+	}
+	if err := appMetrics.Register(buildinfo.Collector()); err != nil {
+		return nil, fmt.Errorf("failed to register build metrics: %w", err)
+	}
+	if err := appMetrics.Register(runtimetune.Collector()); err != nil {
+		return nil, fmt.Errorf("failed to register runtime metrics: %w", err)
+	}
+	return appMetrics, nil
+}
+
+func (s *Server) applyConfig(change *config.Change) {
+	old, cfg := change.Old.Log, change.New.Log
+
+	if cfg.Level != old.Level {
+		if level, err := zapcore.ParseLevel(cfg.Level); err == nil {
+			s.logger.Levels().SetLevel(level)
+		}
+	}
+	if cfg.SamplingInitial != old.SamplingInitial || cfg.SamplingThereafter != old.SamplingThereafter ||
+		cfg.SamplingTick != old.SamplingTick {
+		s.logger.SetSampling(&logger.SamplingConfig{
+			Tick:       cfg.SamplingTick,
+			Initial:    cfg.SamplingInitial,
+			Thereafter: cfg.SamplingThereafter,
+		})
+	}
+	if cfg.RateLimit != old.RateLimit || cfg.RateBurst != old.RateBurst {
+		s.logger.SetRateLimit(&logger.RateLimitConfig{Rate: cfg.RateLimit, Burst: cfg.RateBurst})
+	}
 }
 
 func (s *Server) Start() error {
@@ -100,22 +229,44 @@ func (s *Server) Start() error {
 		"idle_timeout", s.config.Web.IdleTimeout,
 	)
 
-	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
-		return fmt.Errorf("server error: %w", err)
+	serverErrors := make(chan error, 2)
+	go func() {
+		serverErrors <- listen(s.httpServer)
+	}()
+
+	if s.adminServer != nil {
+		s.logger.Infow("admin server starting", "address", s.adminServer.Addr)
+		go func() {
+			serverErrors <- listen(s.adminServer)
+		}()
 	}
 
+	return <-serverErrors
+}
+
+func listen(server *http.Server) error {
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return fmt.Errorf("server error on %s: %w", server.Addr, err)
+	}
 	return nil
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
+	timeout := s.watcher.Current().Web.ShutdownTimeout
 	s.logger.Infow("initiating graceful shutdown",
 		"service", s.config.ServiceName,
-		"shutdown_timeout", s.config.Web.ShutdownTimeout,
+		"shutdown_timeout", timeout,
 	)
 
-	shutdownCtx, cancel := context.WithTimeout(ctx, s.config.Web.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(shutdownCtx); err != nil {
+			s.logger.Warnw("could not stop admin server gracefully", "error", err)
+		}
+	}
+
 	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("could not stop server gracefully: %w", err)
 	}
@@ -132,5 +283,10 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		"service", s.config.ServiceName,
 	)
 
+	// Flushed last so the entries above are exported too.
+	if err := s.logger.Shutdown(shutdownCtx); err != nil {
+		s.logger.Warnw("error shutting down log export", "error", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,158 @@
+package slo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	objectiveAvailability = "availability"
+	objectiveLatency      = "latency"
+)
+
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+type RuleGroup struct {
+	Name     string `yaml:"name"`
+	Interval string `yaml:"interval,omitempty"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// burnRateWindow is one multi-window, multi-burn-rate alert as described in
+// the Google SRE workbook: the alert fires when both the long and the short
+// window burn the error budget faster than burnRate.
+type burnRateWindow struct {
+	long     string
+	short    string
+	burnRate float64
+	severity string
+	forTime  string
+}
+
+var burnRateWindows = []burnRateWindow{
+	{long: "1h", short: "5m", burnRate: 14.4, severity: "page", forTime: "2m"},
+	{long: "6h", short: "30m", burnRate: 6, severity: "page", forTime: "15m"},
+	{long: "1d", short: "2h", burnRate: 3, severity: "ticket", forTime: "1h"},
+	{long: "3d", short: "6h", burnRate: 1, severity: "ticket", forTime: "3h"},
+}
+
+var recordingWindows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+// GenerateRules builds the recording and alerting rules for every objective.
+func GenerateRules(slos config.SLOs) *RuleFile {
+	file := &RuleFile{}
+
+	for _, slo := range slos {
+		file.Groups = append(file.Groups, objectiveGroups(slo.Route, objectiveAvailability, slo.Availability)...)
+		if slo.LatencyThreshold > 0 {
+			file.Groups = append(file.Groups, objectiveGroups(slo.Route, objectiveLatency, slo.LatencyTarget)...)
+		}
+	}
+
+	return file
+}
+
+func MarshalRules(slos config.SLOs) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(GenerateRules(slos)); err != nil {
+		return nil, fmt.Errorf("failed to marshal slo rules: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal slo rules: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func objectiveGroups(route, objective string, target float64) []RuleGroup {
+	labels := map[string]string{"route": route, "slo": objective}
+	errorBudget := 1 - target/100
+
+	recording := RuleGroup{
+		Name:     fmt.Sprintf("slo:%s:%s:recording", slug(route), objective),
+		Interval: "30s",
+	}
+	for _, window := range recordingWindows {
+		recording.Rules = append(recording.Rules, Rule{
+			Record: "slo:sli_error:ratio_rate" + window,
+			Expr:   errorRatioExpr(route, objective, window),
+			Labels: labels,
+		})
+	}
+
+	alerting := RuleGroup{Name: fmt.Sprintf("slo:%s:%s:alerting", slug(route), objective)}
+	for _, window := range burnRateWindows {
+		threshold := formatFloat(window.burnRate * errorBudget)
+		selector := fmt.Sprintf(`{route=%q,slo=%q}`, route, objective)
+
+		alerting.Rules = append(alerting.Rules, Rule{
+			Alert: "SLOErrorBudgetBurn",
+			Expr: fmt.Sprintf(
+				"slo:sli_error:ratio_rate%s%s > %s\nand\nslo:sli_error:ratio_rate%s%s > %s",
+				window.long, selector, threshold, window.short, selector, threshold,
+			),
+			For: window.forTime,
+			Labels: map[string]string{
+				"route":        route,
+				"slo":          objective,
+				"severity":     window.severity,
+				"long_window":  window.long,
+				"short_window": window.short,
+			},
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("%s SLO of %s is burning its error budget", objective, route),
+				"description": fmt.Sprintf(
+					"The %s error ratio of %s over %s and %s exceeds %sx the budget of a %s%% objective.",
+					objective, route, window.long, window.short, formatFloat(window.burnRate), formatFloat(target),
+				),
+			},
+		})
+	}
+
+	return []RuleGroup{recording, alerting}
+}
+
+func errorRatioExpr(route, objective, window string) string {
+	total := fmt.Sprintf(`sum(rate(sli_requests_total{route=%q}[%s]))`, route, window)
+
+	if objective == objectiveLatency {
+		good := fmt.Sprintf(`sum(rate(sli_latency_good_total{route=%q}[%s]))`, route, window)
+		return fmt.Sprintf("1 - (%s / %s)", good, total)
+	}
+
+	errors := fmt.Sprintf(`sum(rate(sli_errors_total{route=%q}[%s]))`, route, window)
+	return fmt.Sprintf("%s / %s", errors, total)
+}
+
+func slug(route string) string {
+	slug := strings.Trim(strings.NewReplacer("/", "_", "{", "", "}", "", "*", "any").Replace(route), "_")
+	if slug == "" {
+		return "root"
+	}
+	return slug
+}
+
+// formatFloat trims floating point noise such as 0.014399999999998414.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', 10, 64)
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	errorQueueSize   = 512
+	errorExportBatch = 64
+)
+
+// newErrorSpanProcessor exports spans that were recorded but not sampled
+// when they end with an error status or a recorded exception. The sampled
+// span processor only exports sampled spans, so without it the record-only
+// decisions made by recordUnsampled would never leave the process.
+func newErrorSpanProcessor(exporter sdktrace.SpanExporter, health *exporterHealth) *batchProcessor {
+	return newBatchProcessor(&batchConfig{
+		Exporter: exporter,
+		Health:   health,
+		Accept: func(s sdktrace.ReadOnlySpan) bool {
+			return !s.SpanContext().IsSampled() && failed(s)
+		},
+		QueueSize: errorQueueSize,
+		BatchSize: errorExportBatch,
+		Interval:  batchTimeout,
+	})
+}
+
+func failed(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+
+	for _, event := range s.Events() {
+		if event.Name == semconv.ExceptionEventName {
+			return true
+		}
+	}
+	return false
+}
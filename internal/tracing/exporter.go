@@ -0,0 +1,209 @@
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+const defaultTracesPath = "/v1/traces"
+
+type ExporterConfig struct {
+	Name        string
+	Protocol    string
+	Endpoint    string
+	Headers     map[string]string
+	Compression string
+	Insecure    bool
+	Certificate string
+	ClientCert  string
+	ClientKey   string
+	FilePath    string
+}
+
+// NewExporter validates cfg and builds the selected span exporter. The none
+// exporter returns a nil exporter: spans are still created and propagated but
+// never leave the process.
+func NewExporter(ctx context.Context, cfg *ExporterConfig) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Name) {
+	case config.ExporterOTLP:
+		return newOTLPExporter(ctx, cfg)
+	case config.ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.ExporterFile:
+		return newFileExporter(cfg.FilePath)
+	case config.ExporterNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf(
+			"unsupported traces exporter %q: expected one of %s, %s, %s or %s",
+			cfg.Name, config.ExporterOTLP, config.ExporterStdout, config.ExporterFile, config.ExporterNone,
+		)
+	}
+}
+
+func newOTLPExporter(ctx context.Context, cfg *ExporterConfig) (sdktrace.SpanExporter, error) {
+	endpoint, insecure, err := parseEndpoint(cfg.Endpoint, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Compression != "" && cfg.Compression != "none" && cfg.Compression != "gzip" {
+		return nil, fmt.Errorf("unsupported otlp compression %q: expected gzip or none", cfg.Compression)
+	}
+	gzip := cfg.Compression == "gzip"
+
+	var tlsConfig *tls.Config
+	if !insecure {
+		if tlsConfig, err = newTLSConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Protocol {
+	case config.ProtocolHTTP:
+		path := endpoint.Path
+		if path == "" || path == "/" {
+			path = defaultTracesPath
+		}
+
+		options := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint.Host),
+			otlptracehttp.WithURLPath(path),
+			otlptracehttp.WithHeaders(cfg.Headers),
+		}
+		if insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		} else {
+			options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+		if gzip {
+			options = append(options, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		}
+
+		return otlptracehttp.New(ctx, options...)
+
+	case config.ProtocolGRPC:
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint.Host),
+			otlptracegrpc.WithHeaders(cfg.Headers),
+		}
+		if insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		} else {
+			options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		if gzip {
+			options = append(options, otlptracegrpc.WithCompressor("gzip"))
+		}
+
+		return otlptracegrpc.New(ctx, options...)
+
+	default:
+		return nil, fmt.Errorf(
+			"unsupported otlp protocol %q: expected %s or %s", cfg.Protocol, config.ProtocolHTTP, config.ProtocolGRPC,
+		)
+	}
+}
+
+// parseEndpoint accepts either a URL, whose scheme decides whether TLS is
+// used, or a bare host:port which relies on the insecure flag.
+func parseEndpoint(endpoint string, insecure bool) (*url.URL, bool, error) {
+	if endpoint == "" {
+		return nil, false, errors.New("otlp endpoint is required")
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		return &url.URL{Host: endpoint}, insecure, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid otlp endpoint %q: %w", endpoint, err)
+	}
+	if u.Host == "" {
+		return nil, false, fmt.Errorf("invalid otlp endpoint %q: missing host", endpoint)
+	}
+
+	switch u.Scheme {
+	case "http":
+		return u, true, nil
+	case "https":
+		return u, false, nil
+	default:
+		return nil, false, fmt.Errorf("invalid otlp endpoint %q: scheme must be http or https", endpoint)
+	}
+}
+
+func newTLSConfig(cfg *ExporterConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.Certificate != "" {
+		pem, err := os.ReadFile(cfg.Certificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read otlp certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("otlp certificate %s contains no valid PEM certificates", cfg.Certificate)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return nil, errors.New("otlp client certificate and key must be configured together")
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load otlp client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// fileExporter writes one JSON encoded span per line and closes the file
+// once the exporter is shut down.
+type fileExporter struct {
+	*stdouttrace.Exporter
+	file *os.File
+}
+
+func newFileExporter(path string) (sdktrace.SpanExporter, error) {
+	if path == "" {
+		return nil, errors.New("traces file path is required for the file exporter")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open traces file: %w", err)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &fileExporter{Exporter: exporter, file: file}, nil
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.file.Close())
+}
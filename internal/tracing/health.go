@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	StatusHealthy     = "healthy"
+	StatusDegraded    = "degraded"
+	StatusStarting    = "starting"
+	StatusDisabled    = "disabled"
+	StatusUnavailable = "unavailable"
+)
+
+// Health is a point in time view of the span export pipeline. It never
+// affects readiness: losing the collector must not take the service down.
+type Health struct {
+	Status        string    `json:"status"`
+	Exporter      string    `json:"exporter"`
+	LastSuccess   time.Time `json:"last_success,omitzero"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorAt   time.Time `json:"last_error_at,omitzero"`
+	ExportedSpans uint64    `json:"exported_spans"`
+	DroppedSpans  uint64    `json:"dropped_spans"`
+	QueueSize     int64     `json:"queue_size"`
+}
+
+// UnavailableHealth reports a tracer provider that failed to initialize.
+func UnavailableHealth(exporter string, err error) Health {
+	return Health{Status: StatusUnavailable, Exporter: exporter, LastError: err.Error()}
+}
+
+type exporterHealth struct {
+	exporter    string
+	queued      atomic.Int64
+	exported    atomic.Uint64
+	dropped     atomic.Uint64
+	failures    atomic.Uint64
+	lastSuccess atomic.Int64
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+func (h *exporterHealth) recordSuccess(spans int) {
+	h.exported.Add(uint64(spans))
+	h.lastSuccess.Store(time.Now().UnixNano())
+}
+
+func (h *exporterHealth) recordFailure(spans int, err error) {
+	h.failures.Add(1)
+	h.dropped.Add(uint64(spans))
+
+	h.mu.Lock()
+	h.lastError = err.Error()
+	h.lastErrorAt = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *exporterHealth) snapshot() Health {
+	health := Health{
+		Exporter:      h.exporter,
+		ExportedSpans: h.exported.Load(),
+		DroppedSpans:  h.dropped.Load(),
+		QueueSize:     h.queued.Load(),
+	}
+
+	if nanos := h.lastSuccess.Load(); nanos > 0 {
+		health.LastSuccess = time.Unix(0, nanos).UTC()
+	}
+
+	h.mu.Lock()
+	health.LastError = h.lastError
+	health.LastErrorAt = h.lastErrorAt
+	h.mu.Unlock()
+
+	switch {
+	case !health.LastErrorAt.IsZero() && health.LastErrorAt.After(health.LastSuccess):
+		health.Status = StatusDegraded
+	case health.LastSuccess.IsZero():
+		health.Status = StatusStarting
+	default:
+		health.Status = StatusHealthy
+	}
+
+	return health
+}
+
+// instrumentedExporter records the outcome of every export.
+type instrumentedExporter struct {
+	sdktrace.SpanExporter
+	health *exporterHealth
+}
+
+func (e *instrumentedExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+		e.health.recordFailure(len(spans), err)
+		return err
+	}
+
+	e.health.recordSuccess(len(spans))
+	return nil
+}
+
+type healthCollector struct {
+	health          *exporterHealth
+	lastSuccessDesc *prometheus.Desc
+	exportedDesc    *prometheus.Desc
+	droppedDesc     *prometheus.Desc
+	failuresDesc    *prometheus.Desc
+	queueDesc       *prometheus.Desc
+}
+
+func newHealthCollector(health *exporterHealth) *healthCollector {
+	labels := prometheus.Labels{"exporter": health.exporter}
+
+	return &healthCollector{
+		health: health,
+		lastSuccessDesc: prometheus.NewDesc(
+			"tracing_exporter_last_success_timestamp_seconds",
+			"Unix time of the last successful span export", nil, labels,
+		),
+		exportedDesc: prometheus.NewDesc(
+			"tracing_spans_exported_total",
+			"Total number of spans exported successfully", nil, labels,
+		),
+		droppedDesc: prometheus.NewDesc(
+			"tracing_spans_dropped_total",
+			"Total number of spans dropped because the queue was full or the export failed", nil, labels,
+		),
+		failuresDesc: prometheus.NewDesc(
+			"tracing_export_failures_total",
+			"Total number of failed span export attempts", nil, labels,
+		),
+		queueDesc: prometheus.NewDesc(
+			"tracing_exporter_queue_size",
+			"Number of spans waiting to be exported", nil, labels,
+		),
+	}
+}
+
+func (c *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastSuccessDesc
+	ch <- c.exportedDesc
+	ch <- c.droppedDesc
+	ch <- c.failuresDesc
+	ch <- c.queueDesc
+}
+
+func (c *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	var lastSuccess float64
+	if nanos := c.health.lastSuccess.Load(); nanos > 0 {
+		lastSuccess = float64(nanos) / float64(time.Second)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.lastSuccessDesc, prometheus.GaugeValue, lastSuccess)
+	ch <- prometheus.MustNewConstMetric(c.exportedDesc, prometheus.CounterValue, float64(c.health.exported.Load()))
+	ch <- prometheus.MustNewConstMetric(c.droppedDesc, prometheus.CounterValue, float64(c.health.dropped.Load()))
+	ch <- prometheus.MustNewConstMetric(c.failuresDesc, prometheus.CounterValue, float64(c.health.failures.Load()))
+	ch <- prometheus.MustNewConstMetric(c.queueDesc, prometheus.GaugeValue, float64(c.health.queued.Load()))
+}
@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	maxQueueSize       = 2048
+	maxExportBatchSize = 512
+	batchTimeout       = time.Second * 5
+	exportTimeout      = time.Second * 30
+)
+
+type batchConfig struct {
+	Exporter sdktrace.SpanExporter
+	Health   *exporterHealth
+	// Accept selects the ended spans to export.
+	Accept    func(sdktrace.ReadOnlySpan) bool
+	QueueSize int
+	BatchSize int
+	Interval  time.Duration
+	// ShutdownExporter shuts the exporter down with the processor. Processors
+	// sharing an exporter leave it to the last one shut down.
+	ShutdownExporter bool
+}
+
+// batchProcessor queues accepted spans and exports them in batches. It
+// replaces the SDK batch span processor, whose queue is not observable, so
+// that the spans waiting and the spans dropped because the queue is full
+// are counted where they are enqueued.
+type batchProcessor struct {
+	config *batchConfig
+	queue  chan sdktrace.ReadOnlySpan
+	flush  chan chan struct{}
+	done   chan struct{}
+
+	// mu guards queue against sends after Shutdown has closed it.
+	mu      sync.RWMutex
+	stopped bool
+}
+
+func newBatchProcessor(cfg *batchConfig) *batchProcessor {
+	p := &batchProcessor{
+		config: cfg,
+		queue:  make(chan sdktrace.ReadOnlySpan, cfg.QueueSize),
+		flush:  make(chan chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *batchProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *batchProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !p.config.Accept(s) {
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return
+	}
+
+	select {
+	case p.queue <- s:
+		p.config.Health.queued.Add(1)
+	default:
+		p.config.Health.dropped.Add(1)
+	}
+}
+
+// Shutdown exports the queued spans and stops the processor.
+func (p *batchProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if p.config.ShutdownExporter {
+		return p.config.Exporter.Shutdown(ctx)
+	}
+	return nil
+}
+
+// ForceFlush returns once the spans queued before the call are exported.
+func (p *batchProcessor) ForceFlush(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	select {
+	case p.flush <- flushed:
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *batchProcessor) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	batch := make([]sdktrace.ReadOnlySpan, 0, p.config.BatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+
+		_ = p.config.Exporter.ExportSpans(ctx, batch)
+		clear(batch)
+		batch = batch[:0]
+	}
+	add := func(span sdktrace.ReadOnlySpan) {
+		p.config.Health.queued.Add(-1)
+		batch = append(batch, span)
+		if len(batch) == p.config.BatchSize {
+			export()
+		}
+	}
+
+	for {
+		select {
+		case span, ok := <-p.queue:
+			if !ok {
+				export()
+				return
+			}
+			add(span)
+		case flushed := <-p.flush:
+			for len(p.queue) > 0 {
+				add(<-p.queue)
+			}
+			export()
+			close(flushed)
+		case <-ticker.C:
+			export()
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testExporter records exported spans. Exports block while block is open and
+// fail with err when set.
+type testExporter struct {
+	started chan struct{}
+	block   chan struct{}
+	err     error
+
+	mu       sync.Mutex
+	spans    int
+	shutdown bool
+}
+
+func (e *testExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.started != nil {
+		select {
+		case e.started <- struct{}{}:
+		default:
+		}
+	}
+	if e.block != nil {
+		<-e.block
+	}
+	if e.err != nil {
+		return e.err
+	}
+
+	e.mu.Lock()
+	e.spans += len(spans)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *testExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	e.shutdown = true
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *testExporter) exported() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.spans
+}
+
+func newTestProcessor(exporter *testExporter, queueSize, batchSize int) (*batchProcessor, *exporterHealth) {
+	health := &exporterHealth{exporter: "test"}
+	processor := newBatchProcessor(&batchConfig{
+		Exporter:  &instrumentedExporter{SpanExporter: exporter, health: health},
+		Health:    health,
+		Accept:    func(sdktrace.ReadOnlySpan) bool { return true },
+		QueueSize: queueSize,
+		BatchSize: batchSize,
+		// Batches are only exported once full, flushed or shut down.
+		Interval:         time.Hour,
+		ShutdownExporter: true,
+	})
+	return processor, health
+}
+
+func endSpans(processor *batchProcessor, n int) {
+	for range n {
+		processor.OnEnd(tracetest.SpanStub{Name: "test"}.Snapshot())
+	}
+}
+
+func TestBatchProcessorDropsWhenQueueFull(t *testing.T) {
+	exporter := &testExporter{started: make(chan struct{}, 1), block: make(chan struct{})}
+	processor, health := newTestProcessor(exporter, 2, 1)
+
+	// The first span fills a batch whose export blocks, leaving the queue to
+	// take two spans and drop the remaining three.
+	endSpans(processor, 1)
+	<-exporter.started
+	endSpans(processor, 5)
+
+	snapshot := health.snapshot()
+	if snapshot.QueueSize != 2 || snapshot.DroppedSpans != 3 {
+		t.Errorf("queue size = %d, dropped = %d, want 2 and 3", snapshot.QueueSize, snapshot.DroppedSpans)
+	}
+
+	close(exporter.block)
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := exporter.exported(); got != 3 {
+		t.Errorf("exported %d spans, want 3", got)
+	}
+	if got := health.snapshot().QueueSize; got != 0 {
+		t.Errorf("queue size after shutdown = %d, want 0", got)
+	}
+}
+
+func TestBatchProcessorForceFlush(t *testing.T) {
+	exporter := &testExporter{}
+	processor, health := newTestProcessor(exporter, 10, 10)
+	defer processor.Shutdown(context.Background())
+
+	endSpans(processor, 3)
+	if err := processor.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := exporter.exported(); got != 3 {
+		t.Errorf("exported %d spans, want 3", got)
+	}
+	if snapshot := health.snapshot(); snapshot.ExportedSpans != 3 || snapshot.QueueSize != 0 {
+		t.Errorf("exported = %d, queue size = %d, want 3 and 0", snapshot.ExportedSpans, snapshot.QueueSize)
+	}
+}
+
+func TestBatchProcessorShutdown(t *testing.T) {
+	exporter := &testExporter{}
+	processor, health := newTestProcessor(exporter, 10, 10)
+
+	endSpans(processor, 3)
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := exporter.exported(); got != 3 {
+		t.Errorf("exported %d spans on shutdown, want 3", got)
+	}
+	if !exporter.shutdown {
+		t.Error("exporter not shut down")
+	}
+
+	// Spans ending after shutdown are neither queued nor exported.
+	endSpans(processor, 2)
+	if err := processor.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := exporter.exported(); got != 3 {
+		t.Errorf("exported %d spans after shutdown, want 3", got)
+	}
+	if got := health.snapshot().QueueSize; got != 0 {
+		t.Errorf("queue size = %d, want 0", got)
+	}
+}
+
+func TestBatchProcessorExportFailure(t *testing.T) {
+	exporter := &testExporter{err: errors.New("collector unavailable")}
+	processor, health := newTestProcessor(exporter, 10, 10)
+	defer processor.Shutdown(context.Background())
+
+	endSpans(processor, 4)
+	if err := processor.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := health.snapshot()
+	if snapshot.Status != StatusDegraded || snapshot.LastError != "collector unavailable" {
+		t.Errorf("status = %q, last error = %q, want %q and the export error",
+			snapshot.Status, snapshot.LastError, StatusDegraded)
+	}
+
+	expected := `
+# HELP tracing_export_failures_total Total number of failed span export attempts
+# TYPE tracing_export_failures_total counter
+tracing_export_failures_total{exporter="test"} 1
+# HELP tracing_spans_dropped_total Total number of spans dropped because the queue was full or the export failed
+# TYPE tracing_spans_dropped_total counter
+tracing_spans_dropped_total{exporter="test"} 4
+# HELP tracing_spans_exported_total Total number of spans exported successfully
+# TYPE tracing_spans_exported_total counter
+tracing_spans_exported_total{exporter="test"} 0
+`
+	err := testutil.CollectAndCompare(newHealthCollector(health), strings.NewReader(expected),
+		"tracing_export_failures_total", "tracing_spans_dropped_total", "tracing_spans_exported_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
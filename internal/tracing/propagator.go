@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// NewPropagator composes the propagators named as in OTEL_PROPAGATORS. The
+// composite extracts every configured format and injects all of them, so
+// mixed estates keep a single trace across B3, Jaeger and W3C services.
+func NewPropagator(names []string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		case "none":
+			return propagation.NewCompositeTextMapPropagator(), nil
+		case "":
+		default:
+			return nil, fmt.Errorf(
+				"unsupported propagator %q: expected tracecontext, baggage, b3, b3multi, jaeger or none", name,
+			)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
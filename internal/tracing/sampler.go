@@ -0,0 +1,183 @@
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sampler names as defined for OTEL_TRACES_SAMPLER by the OpenTelemetry SDK
+// environment variable specification.
+const (
+	samplerAlwaysOn                = "always_on"
+	samplerAlwaysOff               = "always_off"
+	samplerTraceIDRatio            = "traceidratio"
+	samplerParentBasedAlwaysOn     = "parentbased_always_on"
+	samplerParentBasedAlwaysOff    = "parentbased_always_off"
+	samplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+type SamplerConfig struct {
+	Name          string
+	Arg           string
+	Environment   string
+	SampleErrors  bool
+	RouteSampling config.RouteSampling
+}
+
+// NewSampler builds the head sampler. Without an explicit OTEL_TRACES_SAMPLER
+// it honours the parent's decision and samples root spans at a ratio chosen
+// from the environment. Route overrides take precedence over everything else.
+//
+// SampleErrors records the spans the sampler would drop, except those of
+// never routes, so that the error span processor can export the ones that
+// fail. Every request then pays for a fully recorded span, and the exported
+// error spans arrive without their unsampled parents and siblings.
+func NewSampler(cfg *SamplerConfig) (sdktrace.Sampler, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Name))
+	if name == "" {
+		name = samplerParentBasedTraceIDRatio
+	}
+
+	ratio := defaultRatioForEnvironment(cfg.Environment)
+	if cfg.Arg != "" && strings.HasSuffix(name, samplerTraceIDRatio) {
+		parsed, err := strconv.ParseFloat(cfg.Arg, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return nil, fmt.Errorf("invalid sampler argument %q: expected a ratio in [0, 1]", cfg.Arg)
+		}
+		ratio = parsed
+	}
+
+	var sampler sdktrace.Sampler
+	switch name {
+	case samplerAlwaysOn:
+		sampler = sdktrace.AlwaysSample()
+	case samplerAlwaysOff:
+		sampler = sdktrace.NeverSample()
+	case samplerTraceIDRatio:
+		sampler = sdktrace.TraceIDRatioBased(ratio)
+	case samplerParentBasedAlwaysOn:
+		sampler = sdktrace.ParentBased(sdktrace.AlwaysSample())
+	case samplerParentBasedAlwaysOff:
+		sampler = sdktrace.ParentBased(sdktrace.NeverSample())
+	case samplerParentBasedTraceIDRatio:
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	default:
+		return nil, fmt.Errorf("unsupported sampler %q", cfg.Name)
+	}
+
+	if cfg.SampleErrors {
+		sampler = &recordUnsampled{delegate: sampler}
+	}
+
+	if len(cfg.RouteSampling) > 0 {
+		sampler = newRouteSampler(sampler, cfg.RouteSampling)
+	}
+
+	return sampler, nil
+}
+
+func defaultRatioForEnvironment(environment string) float64 {
+	switch environment {
+	case config.EnvProduction:
+		return 0.2
+	case config.EnvDevelopment:
+		return 1
+	default:
+		return 0.5
+	}
+}
+
+// routeSampler applies per-route overrides using the url.path attribute that
+// TracingMiddleware sets when it starts the server span. Ratio rules honour
+// the parent's decision like the default sampler.
+type routeSampler struct {
+	delegate sdktrace.Sampler
+	rules    config.RouteSampling
+	ratios   []sdktrace.Sampler
+}
+
+func newRouteSampler(delegate sdktrace.Sampler, rules config.RouteSampling) *routeSampler {
+	ratios := make([]sdktrace.Sampler, len(rules))
+	for i, rule := range rules {
+		if rule.Decision == config.SamplingRatio {
+			ratios[i] = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(rule.Ratio))
+		}
+	}
+	return &routeSampler{delegate: delegate, rules: rules, ratios: ratios}
+}
+
+func (s *routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	path, ok := pathAttribute(p)
+	if !ok {
+		return s.delegate.ShouldSample(p)
+	}
+
+	for i, rule := range s.rules {
+		if !rule.Matches(path) {
+			continue
+		}
+
+		switch rule.Decision {
+		case config.SamplingAlways:
+			return sampled(p, sdktrace.RecordAndSample)
+		case config.SamplingNever:
+			return sampled(p, sdktrace.Drop)
+		default:
+			return s.ratios[i].ShouldSample(p)
+		}
+	}
+
+	return s.delegate.ShouldSample(p)
+}
+
+func (s *routeSampler) Description() string {
+	rules := make([]string, 0, len(s.rules))
+	for _, rule := range s.rules {
+		decision := rule.Decision
+		if decision == config.SamplingRatio {
+			decision = strconv.FormatFloat(rule.Ratio, 'g', -1, 64)
+		}
+		rules = append(rules, rule.Pattern+"="+decision)
+	}
+	return fmt.Sprintf("RouteSampler{routes:[%s],default:%s}", strings.Join(rules, ","), s.delegate.Description())
+}
+
+// recordUnsampled turns drop decisions into record-only ones, keeping the
+// spans out of the exporter unless they end in error.
+type recordUnsampled struct {
+	delegate sdktrace.Sampler
+}
+
+func (s *recordUnsampled) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.delegate.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s *recordUnsampled) Description() string {
+	return fmt.Sprintf("RecordUnsampled{%s}", s.delegate.Description())
+}
+
+func pathAttribute(p sdktrace.SamplingParameters) (string, bool) {
+	for _, attr := range p.Attributes {
+		if attr.Key == semconv.URLPathKey {
+			return attr.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func sampled(p sdktrace.SamplingParameters, decision sdktrace.SamplingDecision) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
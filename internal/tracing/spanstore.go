@@ -0,0 +1,326 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SpansRecent  = "recent"
+	SpansErrors  = "errors"
+	SpansLatency = "latency"
+
+	maxSpanNames          = 256
+	recentSpansPerName    = 32
+	errorSpansPerName     = 16
+	latencySpansPerBucket = 4
+)
+
+// LatencyBuckets are the lower bounds of the latency buckets spans are
+// counted and sampled into, the last bucket being unbounded.
+var LatencyBuckets = []time.Duration{
+	0,
+	time.Microsecond * 10,
+	time.Microsecond * 100,
+	time.Millisecond,
+	time.Millisecond * 10,
+	time.Millisecond * 100,
+	time.Second,
+	time.Second * 10,
+	time.Second * 100,
+}
+
+// SpanStore is a span processor keeping, per span name, the most recent
+// spans, the most recent failed spans and a few samples per latency bucket,
+// so traces can be inspected without a collector. Only spans the sampler
+// records reach it.
+type SpanStore struct {
+	mu    sync.RWMutex
+	names map[string]*spanNameStore
+}
+
+type spanNameStore struct {
+	counts  []uint64
+	errors  uint64
+	recent  *spanRing
+	failed  *spanRing
+	latency []*spanRing
+}
+
+type BucketCount struct {
+	Min   time.Duration `json:"-"`
+	Max   time.Duration `json:"-"`
+	Label string        `json:"label"`
+	Count uint64        `json:"count"`
+}
+
+type SpanSummary struct {
+	Name    string        `json:"name"`
+	Total   uint64        `json:"total"`
+	Errors  uint64        `json:"errors"`
+	Buckets []BucketCount `json:"buckets"`
+}
+
+type SpanEvent struct {
+	Name       string            `json:"name"`
+	Time       time.Time         `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type SpanData struct {
+	TraceID       string            `json:"traceId"`
+	SpanID        string            `json:"spanId"`
+	ParentSpanID  string            `json:"parentSpanId,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Start         time.Time         `json:"start"`
+	End           time.Time         `json:"end"`
+	Duration      time.Duration     `json:"-"`
+	DurationMs    float64           `json:"durationMs"`
+	Status        string            `json:"status"`
+	StatusMessage string            `json:"statusMessage,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Events        []SpanEvent       `json:"events,omitempty"`
+}
+
+type SpanNode struct {
+	SpanData
+	Children []*SpanNode `json:"children,omitempty"`
+}
+
+func NewSpanStore() *SpanStore {
+	return &SpanStore{names: make(map[string]*spanNameStore)}
+}
+
+func (s *SpanStore) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (s *SpanStore) OnEnd(span sdktrace.ReadOnlySpan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store, ok := s.names[span.Name()]
+	if !ok {
+		if len(s.names) >= maxSpanNames {
+			return
+		}
+		store = newSpanNameStore()
+		s.names[span.Name()] = store
+	}
+
+	bucket := latencyBucket(span.EndTime().Sub(span.StartTime()))
+	store.counts[bucket]++
+	store.recent.add(span)
+	store.latency[bucket].add(span)
+
+	if failed(span) {
+		store.errors++
+		store.failed.add(span)
+	}
+}
+
+func (s *SpanStore) Shutdown(context.Context) error   { return nil }
+func (s *SpanStore) ForceFlush(context.Context) error { return nil }
+
+// Summaries returns the span counts per latency bucket for every span name.
+func (s *SpanStore) Summaries() []SpanSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]SpanSummary, 0, len(s.names))
+	for name, store := range s.names {
+		summary := SpanSummary{Name: name, Errors: store.errors, Buckets: make([]BucketCount, len(LatencyBuckets))}
+		for i, count := range store.counts {
+			summary.Total += count
+			summary.Buckets[i] = bucketCount(i, count)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	slices.SortFunc(summaries, func(a, b SpanSummary) int { return strings.Compare(a.Name, b.Name) })
+	return summaries
+}
+
+// Spans returns the stored spans of the given kind for a span name, newest
+// first. The bucket is only used for latency samples.
+func (s *SpanStore) Spans(name, kind string, bucket int) []SpanData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	store, ok := s.names[name]
+	if !ok {
+		return nil
+	}
+
+	var ring *spanRing
+	switch kind {
+	case SpansRecent:
+		ring = store.recent
+	case SpansErrors:
+		ring = store.failed
+	case SpansLatency:
+		if bucket < 0 || bucket >= len(store.latency) {
+			return nil
+		}
+		ring = store.latency[bucket]
+	default:
+		return nil
+	}
+
+	spans := ring.list()
+	data := make([]SpanData, len(spans))
+	for i, span := range spans {
+		data[i] = newSpanData(span)
+	}
+	return data
+}
+
+// Trace assembles the stored spans of a trace into trees ordered by start
+// time. Spans whose parent is not stored become roots, so a partially
+// retained trace is still shown.
+func (s *SpanStore) Trace(traceID trace.TraceID) []*SpanNode {
+	s.mu.RLock()
+	nodes := make(map[trace.SpanID]*SpanNode)
+	for _, store := range s.names {
+		store.each(func(span sdktrace.ReadOnlySpan) {
+			if span.SpanContext().TraceID() != traceID {
+				return
+			}
+			if _, ok := nodes[span.SpanContext().SpanID()]; !ok {
+				nodes[span.SpanContext().SpanID()] = &SpanNode{SpanData: newSpanData(span)}
+			}
+		})
+	}
+	s.mu.RUnlock()
+
+	var roots []*SpanNode
+	for _, node := range nodes {
+		parentID, _ := trace.SpanIDFromHex(node.ParentSpanID)
+		if parent, ok := nodes[parentID]; ok {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+
+	sortNodes(roots)
+	return roots
+}
+
+func newSpanNameStore() *spanNameStore {
+	store := &spanNameStore{
+		counts:  make([]uint64, len(LatencyBuckets)),
+		recent:  newSpanRing(recentSpansPerName),
+		failed:  newSpanRing(errorSpansPerName),
+		latency: make([]*spanRing, len(LatencyBuckets)),
+	}
+	for i := range store.latency {
+		store.latency[i] = newSpanRing(latencySpansPerBucket)
+	}
+	return store
+}
+
+func (s *spanNameStore) each(fn func(sdktrace.ReadOnlySpan)) {
+	rings := append([]*spanRing{s.recent, s.failed}, s.latency...)
+	for _, ring := range rings {
+		for _, span := range ring.spans {
+			fn(span)
+		}
+	}
+}
+
+type spanRing struct {
+	spans []sdktrace.ReadOnlySpan
+	next  int
+}
+
+func newSpanRing(size int) *spanRing {
+	return &spanRing{spans: make([]sdktrace.ReadOnlySpan, 0, size)}
+}
+
+func (r *spanRing) add(span sdktrace.ReadOnlySpan) {
+	if len(r.spans) < cap(r.spans) {
+		r.spans = append(r.spans, span)
+		return
+	}
+	r.spans[r.next] = span
+	r.next = (r.next + 1) % len(r.spans)
+}
+
+// list returns the spans newest first.
+func (r *spanRing) list() []sdktrace.ReadOnlySpan {
+	spans := make([]sdktrace.ReadOnlySpan, 0, len(r.spans))
+	for i := range r.spans {
+		spans = append(spans, r.spans[(r.next+len(r.spans)-1-i)%len(r.spans)])
+	}
+	return spans
+}
+
+func latencyBucket(d time.Duration) int {
+	for i := len(LatencyBuckets) - 1; i > 0; i-- {
+		if d >= LatencyBuckets[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+func bucketCount(i int, count uint64) BucketCount {
+	bucket := BucketCount{Min: LatencyBuckets[i], Count: count}
+	if i+1 < len(LatencyBuckets) {
+		bucket.Max = LatencyBuckets[i+1]
+		bucket.Label = "[" + bucket.Min.String() + ", " + bucket.Max.String() + ")"
+	} else {
+		bucket.Label = ">= " + bucket.Min.String()
+	}
+	return bucket
+}
+
+func newSpanData(span sdktrace.ReadOnlySpan) SpanData {
+	data := SpanData{
+		TraceID:       span.SpanContext().TraceID().String(),
+		SpanID:        span.SpanContext().SpanID().String(),
+		Name:          span.Name(),
+		Kind:          span.SpanKind().String(),
+		Start:         span.StartTime(),
+		End:           span.EndTime(),
+		Duration:      span.EndTime().Sub(span.StartTime()),
+		DurationMs:    float64(span.EndTime().Sub(span.StartTime())) / float64(time.Millisecond),
+		Status:        span.Status().Code.String(),
+		StatusMessage: span.Status().Description,
+		Attributes:    make(map[string]string, len(span.Attributes())),
+	}
+
+	if span.Parent().IsValid() {
+		data.ParentSpanID = span.Parent().SpanID().String()
+	}
+
+	for _, attr := range span.Attributes() {
+		data.Attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+
+	for _, event := range span.Events() {
+		spanEvent := SpanEvent{Name: event.Name, Time: event.Time}
+		if len(event.Attributes) > 0 {
+			spanEvent.Attributes = make(map[string]string, len(event.Attributes))
+			for _, attr := range event.Attributes {
+				spanEvent.Attributes[string(attr.Key)] = attr.Value.Emit()
+			}
+		}
+		data.Events = append(data.Events, spanEvent)
+	}
+
+	return data
+}
+
+func sortNodes(nodes []*SpanNode) {
+	slices.SortFunc(nodes, func(a, b *SpanNode) int { return a.Start.Compare(b.Start) })
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+}
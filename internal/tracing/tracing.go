@@ -2,14 +2,18 @@ package tracing
 
 import (
 	"context"
-	"time"
+	"fmt"
 
+	"github.com/iamBelugaa/k8s-demo/internal/config"
+	"github.com/iamBelugaa/k8s-demo/pkg/buildinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/k8sinfo"
+	"github.com/iamBelugaa/k8s-demo/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -17,43 +21,129 @@ type TracingConfig struct {
 	ServiceName    string
 	ServiceVersion string
 	Environment    string
-	JaegerEndpoint string
+	Exporter       *ExporterConfig
+	Propagators    []string
+	Sampler        *SamplerConfig
+	Log            *logger.Logger
 }
 
-func New(config *TracingConfig) (func(context.Context) error, error) {
-	exporter, err := otlptracehttp.New(
-		context.Background(),
-		otlptracehttp.WithEndpoint(config.JaegerEndpoint),
-		otlptracehttp.WithInsecure(),
-	)
+type Provider struct {
+	tp       *sdktrace.TracerProvider
+	sampler  sdktrace.Sampler
+	exporter string
+	health   *exporterHealth
+	spans    *SpanStore
+}
+
+func New(config *TracingConfig) (*Provider, error) {
+	// Export failures and other SDK errors are otherwise written to stderr
+	// through the standard library logger.
+	otelLog := config.Log.Named("otel")
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		otelLog.Warnw("opentelemetry error", "error", err)
+	}))
+
+	sampler, err := NewSampler(config.Sampler)
 	if err != nil {
 		return nil, err
 	}
 
-	resource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String(config.ServiceName),
-		semconv.ServiceVersionKey.String(config.ServiceVersion),
-		semconv.DeploymentEnvironmentKey.String(config.Environment),
-	)
-
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(
-			exporter,
-			sdktrace.WithMaxExportBatchSize(512),
-			sdktrace.WithBatchTimeout(time.Second*5),
-			sdktrace.WithExportTimeout(time.Second*30),
-		),
+	propagator, err := NewPropagator(config.Propagators)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := NewExporter(context.Background(), config.Exporter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s traces exporter: %w", config.Exporter.Name, err)
+	}
+
+	resource := NewResource(config.ServiceName, config.ServiceVersion, config.Environment)
+
+	spans := NewSpanStore()
+	options := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(resource),
-		sdktrace.WithSampler(getSamplerForEnvironment(config.Environment)),
-	)
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithSpanProcessor(spans),
+	}
+
+	// The error span processor must be registered before the sampled one so
+	// it is shut down while the shared exporter is still running.
+	health := &exporterHealth{exporter: config.Exporter.Name}
+	if exporter != nil {
+		instrumented := &instrumentedExporter{SpanExporter: exporter, health: health}
+		if config.Sampler.SampleErrors {
+			options = append(options, sdktrace.WithSpanProcessor(newErrorSpanProcessor(instrumented, health)))
+		}
+		options = append(options, sdktrace.WithSpanProcessor(newBatchProcessor(&batchConfig{
+			Exporter: instrumented,
+			Health:   health,
+			Accept: func(s sdktrace.ReadOnlySpan) bool {
+				return s.SpanContext().IsSampled()
+			},
+			QueueSize:        maxQueueSize,
+			BatchSize:        maxExportBatchSize,
+			Interval:         batchTimeout,
+			ShutdownExporter: true,
+		})))
+	}
+
+	tp := sdktrace.NewTracerProvider(options...)
 
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(
-		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
-	)
+	otel.SetTextMapPropagator(propagator)
+
+	return &Provider{tp: tp, sampler: sampler, exporter: config.Exporter.Name, health: health, spans: spans}, nil
+}
+
+// NewResource describes the service to the tracer provider and to any other
+// signal exported alongside its traces.
+func NewResource(service, version, environment string) *resource.Resource {
+	build := buildinfo.Get()
 
-	return tp.Shutdown, nil
+	attributes := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(service),
+		semconv.ServiceVersionKey.String(version),
+		semconv.DeploymentEnvironmentKey.String(environment),
+		semconv.ProcessRuntimeNameKey.String("go"),
+		semconv.ProcessRuntimeVersionKey.String(build.GoVersion),
+		attribute.String("build.commit", build.Commit),
+		attribute.Bool("build.dirty", build.Dirty),
+		attribute.String("build.time", build.BuildTime),
+	}
+	attributes = append(attributes, k8sinfo.Get().Attributes()...)
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attributes...)
+}
+
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.tp.Shutdown(ctx)
+}
+
+func (p *Provider) Exporter() string {
+	return p.exporter
+}
+
+func (p *Provider) Health() Health {
+	if p.exporter == config.ExporterNone {
+		return Health{Status: StatusDisabled, Exporter: p.exporter}
+	}
+	return p.health.snapshot()
+}
+
+// Collector exposes the exporter health as Prometheus metrics.
+func (p *Provider) Collector() prometheus.Collector {
+	return newHealthCollector(p.health)
+}
+
+// Spans returns the in-process store of recently ended spans.
+func (p *Provider) Spans() *SpanStore {
+	return p.spans
+}
+
+// SamplerDescription reports the active sampling configuration.
+func (p *Provider) SamplerDescription() string {
+	return p.sampler.Description()
 }
 
 func GetTracer(name string) trace.Tracer {
@@ -66,14 +156,3 @@ func StartSpan(
 	tracer := GetTracer(tracerName)
 	return tracer.Start(ctx, spanName, options...)
 }
-
-func getSamplerForEnvironment(environment string) sdktrace.Sampler {
-	switch environment {
-	case "PRODUCTION":
-		return sdktrace.TraceIDRatioBased(0.2)
-	case "DEVELOPMENT":
-		return sdktrace.AlwaysSample()
-	default:
-		return sdktrace.TraceIDRatioBased(0.5)
-	}
-}
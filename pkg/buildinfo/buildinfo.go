@@ -0,0 +1,132 @@
+// Package buildinfo describes the running binary. Release builds set the
+// version, commit and build time with the linker:
+//
+//	go build -ldflags "-X github.com/iamBelugaa/k8s-demo/pkg/buildinfo.version=v1.2.3 \
+//	  -X github.com/iamBelugaa/k8s-demo/pkg/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X github.com/iamBelugaa/k8s-demo/pkg/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values not set that way are taken from the module and VCS information the
+// Go toolchain embeds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const unknown = "unknown"
+
+// Set with -ldflags -X.
+var (
+	version   string
+	commit    string
+	buildTime string
+	dirty     string
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Dirty     bool   `json:"dirty"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	Module    string `json:"module"`
+	Deps      []Dep  `json:"deps,omitempty"`
+}
+
+type Dep struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Replace is the module replacing Path, if any.
+	Replace string `json:"replace,omitempty"`
+}
+
+// Get returns the build information, read once.
+var Get = sync.OnceValue(read)
+
+func read() *Info {
+	info := &Info{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info.Dirty, _ = strconv.ParseBool(dirty)
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Module = build.Main.Path
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				if dirty == "" {
+					info.Dirty = setting.Value == "true"
+				}
+			}
+		}
+
+		for _, dep := range build.Deps {
+			d := Dep{Path: dep.Path, Version: dep.Version}
+			if dep.Replace != nil {
+				d.Replace = dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			info.Deps = append(info.Deps, d)
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = unknown
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = unknown
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit.
+func (i *Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+// Collector exposes the build_info gauge, always 1, labelled with the build
+// metadata so it can be joined onto other series.
+func Collector() prometheus.Collector {
+	info := Get()
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the running binary, always 1",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"dirty":      strconv.FormatBool(info.Dirty),
+			"build_time": info.BuildTime,
+			"go_version": info.GoVersion,
+		},
+	})
+	gauge.Set(1)
+	return gauge
+}
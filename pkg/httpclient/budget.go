@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// retryBudget caps retries to a ratio of the requests made, plus a small
+// steady allowance, so that a struggling dependency is not hit with a retry
+// storm. Every request deposits ratio tokens and every retry withdraws one.
+type retryBudget struct {
+	mu           sync.Mutex
+	tokens       float64
+	maxTokens    float64
+	ratio        float64
+	minPerSecond float64
+	lastRefill   time.Time
+}
+
+func newRetryBudget(ratio float64, minPerSecond int) *retryBudget {
+	maxTokens := float64(minPerSecond) * 10
+	if maxTokens < 10 {
+		maxTokens = 10
+	}
+
+	return &retryBudget{
+		tokens:       maxTokens,
+		maxTokens:    maxTokens,
+		ratio:        ratio,
+		minPerSecond: float64(minPerSecond),
+		lastRefill:   time.Now(),
+	}
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens = min(b.maxTokens, b.tokens+b.ratio)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+func (b *retryBudget) refill() {
+	now := time.Now()
+	b.tokens = min(b.maxTokens, b.tokens+b.minPerSecond*now.Sub(b.lastRefill).Seconds())
+	b.lastRefill = now
+}
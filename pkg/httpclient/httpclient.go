@@ -0,0 +1,293 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultTimeout      = time.Second * 10
+	defaultMaxRetries   = 2
+	defaultBaseBackoff  = time.Millisecond * 100
+	defaultMaxBackoff   = time.Second * 2
+	defaultRetryBudget  = 0.2
+	defaultMinRetries   = 5
+	idempotencyKeyField = "Idempotency-Key"
+)
+
+type Config struct {
+	// Service names the tracer used for client spans.
+	Service string
+	// Timeout bounds a whole call, retries included, unless overridden per
+	// call with WithTimeout. It defaults to 10s when not positive.
+	Timeout time.Duration
+	// MaxRetries defaults to 2; a negative value disables retries.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// RetryBudget is the ratio of retries allowed per request on top of
+	// MinRetriesPerSecond.
+	RetryBudget         float64
+	MinRetriesPerSecond int
+	Transport           http.RoundTripper
+	Registerer          prometheus.Registerer
+}
+
+type Client struct {
+	service     string
+	timeout     time.Duration
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	httpClient  *http.Client
+	budget      *retryBudget
+	metrics     *clientMetrics
+}
+
+type callOptions struct {
+	timeout time.Duration
+}
+
+type CallOption func(*callOptions)
+
+// WithTimeout overrides the client timeout for a single call. A timeout that
+// is not positive keeps the client timeout.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+func New(cfg *Config) (*Client, error) {
+	reg := cfg.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	clientMetrics, err := newClientMetrics(reg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register http client metrics: %w", err)
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	maxRetries := orDefault(cfg.MaxRetries, defaultMaxRetries)
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	return &Client{
+		service:     cfg.Service,
+		timeout:     timeout,
+		maxRetries:  maxRetries,
+		baseBackoff: orDefault(cfg.BaseBackoff, defaultBaseBackoff),
+		maxBackoff:  orDefault(cfg.MaxBackoff, defaultMaxBackoff),
+		httpClient:  &http.Client{Transport: transport},
+		budget:      newRetryBudget(orDefault(cfg.RetryBudget, defaultRetryBudget), orDefault(cfg.MinRetriesPerSecond, defaultMinRetries)),
+		metrics:     clientMetrics,
+	}, nil
+}
+
+func (c *Client) Get(ctx context.Context, url string, opts ...CallOption) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req, opts...)
+}
+
+// Do sends req, creating a client span per attempt and injecting the trace
+// context with the global propagator. Idempotent requests are retried on
+// network errors and retryable statuses while the retry budget allows. The
+// call timeout keeps running until the returned body is closed.
+func (c *Client) Do(req *http.Request, opts ...CallOption) (*http.Response, error) {
+	options := &callOptions{timeout: c.timeout}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), options.timeout)
+
+	c.budget.deposit()
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := prepareAttempt(ctx, req, attempt)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		resp, err := c.send(attemptReq, attempt)
+
+		if !retryable || attempt >= c.maxRetries || !shouldRetry(ctx, resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if !c.budget.withdraw() {
+			c.metrics.budgetExhausted.WithLabelValues(req.URL.Host).Inc()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		c.metrics.retriesTotal.WithLabelValues(req.URL.Host).Inc()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) send(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, span := otel.Tracer(c.service).Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.Redacted()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	if attempt > 0 {
+		span.SetAttributes(semconv.HTTPRequestResendCount(attempt))
+	}
+
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	duration := time.Since(start).Seconds()
+
+	host := req.URL.Host
+	c.metrics.requestDuration.WithLabelValues(host, req.Method).Observe(duration)
+
+	if err != nil {
+		c.metrics.requestsTotal.WithLabelValues(host, req.Method, "error").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	c.metrics.requestsTotal.WithLabelValues(host, req.Method, strconv.Itoa(resp.StatusCode)).Inc()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+
+	return resp, nil
+}
+
+// backoff returns an exponential delay with full jitter, honouring a
+// Retry-After header in seconds when the server sent one.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.maxBackoff)
+		}
+	}
+
+	ceiling := min(c.baseBackoff<<attempt, c.maxBackoff)
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func prepareAttempt(ctx context.Context, req *http.Request, attempt int) (*http.Request, error) {
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, nil
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(idempotencyKeyField) != ""
+	}
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func orDefault[T comparable](value, fallback T) T {
+	var zero T
+	if value == zero {
+		return fallback
+	}
+	return value
+}
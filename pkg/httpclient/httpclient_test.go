@@ -0,0 +1,237 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestClient(t *testing.T, cfg *Config) *Client {
+	t.Helper()
+
+	cfg.Service = "test"
+	cfg.BaseBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond * 5
+	cfg.Registerer = prometheus.NewRegistry()
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// newStatusServer responds with statuses in turn, repeating the last one, and
+// counts the requests it receives.
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		header     http.Header
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{
+			name:       "idempotent method retried on 503",
+			method:     http.MethodGet,
+			statuses:   []int{503, 503, 200},
+			wantStatus: 200,
+			wantCalls:  3,
+		},
+		{
+			name:       "retries stop at the limit",
+			method:     http.MethodPut,
+			statuses:   []int{502},
+			wantStatus: 502,
+			wantCalls:  3,
+		},
+		{
+			name:       "500 is not retried",
+			method:     http.MethodGet,
+			statuses:   []int{500, 200},
+			wantStatus: 500,
+			wantCalls:  1,
+		},
+		{
+			name:       "post is not retried",
+			method:     http.MethodPost,
+			statuses:   []int{503, 200},
+			wantStatus: 503,
+			wantCalls:  1,
+		},
+		{
+			name:       "post with idempotency key is retried",
+			method:     http.MethodPost,
+			header:     http.Header{idempotencyKeyField: []string{"abc"}},
+			statuses:   []int{503, 200},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newStatusServer(t, tt.statuses...)
+			client := newTestClient(t, &Config{})
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, strings.NewReader("body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server received %d requests, want %d", got, tt.wantCalls)
+			}
+
+			retries := testutil.ToFloat64(client.metrics.retriesTotal.WithLabelValues(req.URL.Host))
+			if want := float64(tt.wantCalls - 1); retries != want {
+				t.Errorf("retries = %v, want %v", retries, want)
+			}
+		})
+	}
+}
+
+func TestDoRetryBudgetExhausted(t *testing.T) {
+	server, calls := newStatusServer(t, 503, 200)
+	client := newTestClient(t, &Config{})
+
+	for client.budget.withdraw() {
+	}
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status = %d after %d requests, want 503 after 1", resp.StatusCode, calls.Load())
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if got := testutil.ToFloat64(client.metrics.budgetExhausted.WithLabelValues(host)); got != 1 {
+		t.Errorf("budget exhausted = %v, want 1", got)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := newRetryBudget(0.5, 1)
+
+	withdrawn := 0
+	for budget.withdraw() {
+		withdrawn++
+	}
+	if withdrawn != 10 {
+		t.Fatalf("withdrew %d retries from a full budget, want 10", withdrawn)
+	}
+
+	// Every request adds half a retry.
+	budget.deposit()
+	if budget.withdraw() {
+		t.Error("withdrew a retry after a single deposit")
+	}
+	budget.deposit()
+	if !budget.withdraw() {
+		t.Error("no retry after two deposits")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	client := newTestClient(t, &Config{})
+
+	for attempt := range 20 {
+		if delay := client.backoff(attempt, nil); delay < 0 || delay > client.maxBackoff {
+			t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, delay, client.maxBackoff)
+		}
+	}
+
+	tests := []struct {
+		retryAfter string
+		want       time.Duration
+	}{
+		{retryAfter: "0", want: 0},
+		{retryAfter: "60", want: client.maxBackoff},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{tt.retryAfter}}}
+		if got := client.backoff(0, resp); got != tt.want {
+			t.Errorf("backoff with Retry-After %s = %v, want %v", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestDoTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := newTestClient(t, &Config{Timeout: time.Second * 5})
+
+	tests := []struct {
+		name    string
+		path    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "per call timeout", path: "/slow", timeout: time.Millisecond * 20, wantErr: true},
+		{name: "zero keeps the client timeout", path: "/", timeout: 0},
+		{name: "negative keeps the client timeout", path: "/", timeout: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(context.Background(), server.URL+tt.path, WithTimeout(tt.timeout))
+			if tt.wantErr {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("got %v, want a deadline error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type clientMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	retriesTotal    *prometheus.CounterVec
+	budgetExhausted *prometheus.CounterVec
+}
+
+// newClientMetrics registers the outbound metrics, reusing the collectors of
+// a previously created client so several clients can share a registerer.
+func newClientMetrics(reg prometheus.Registerer) (*clientMetrics, error) {
+	requestsTotal, err := register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_requests_total",
+			Help: "Total number of outbound HTTP requests",
+		},
+		[]string{"host", "method", "status_code"},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	requestDuration, err := register(reg, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Duration of outbound HTTP requests in seconds, per attempt",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"host", "method"},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	retriesTotal, err := register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_retries_total",
+			Help: "Total number of outbound HTTP request retries",
+		},
+		[]string{"host"},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	budgetExhausted, err := register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_retry_budget_exhausted_total",
+			Help: "Number of retries skipped because the retry budget was exhausted",
+		},
+		[]string{"host"},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientMetrics{
+		requestsTotal:   requestsTotal,
+		requestDuration: requestDuration,
+		retriesTotal:    retriesTotal,
+		budgetExhausted: budgetExhausted,
+	}, nil
+}
+
+func register[T prometheus.Collector](reg prometheus.Registerer, collector T) (T, error) {
+	if err := reg.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
@@ -0,0 +1,206 @@
+// Package k8sinfo describes the pod the service runs in, from the Downward
+// API environment variables and volume files and from the process cgroup.
+// Outside Kubernetes every value is empty.
+package k8sinfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// DefaultPodInfoDir is where the chart mounts the Downward API volume,
+// overridden by the PODINFO_DIR environment variable.
+const DefaultPodInfoDir = "/etc/podinfo"
+
+// containerIDPattern matches the 64 hex digit IDs used by Docker, containerd
+// and CRI-O in cgroup paths.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+type Info struct {
+	PodName       string            `json:"podName,omitempty"`
+	PodNamespace  string            `json:"podNamespace,omitempty"`
+	PodUID        string            `json:"podUid,omitempty"`
+	PodIP         string            `json:"podIp,omitempty"`
+	NodeName      string            `json:"nodeName,omitempty"`
+	ContainerName string            `json:"containerName,omitempty"`
+	ContainerID   string            `json:"containerId,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	// Resources holds the container requests and limits exposed as Downward
+	// API files, keyed by file name such as cpu_limit or mem_limit.
+	Resources map[string]string `json:"resources,omitempty"`
+}
+
+type Config struct {
+	// PodInfoDir holds the Downward API volume files.
+	PodInfoDir string
+	// CgroupFile and MountInfoFile are read for the container ID.
+	CgroupFile    string
+	MountInfoFile string
+}
+
+// Get returns the information of the current pod, detected once.
+var Get = sync.OnceValue(func() *Info {
+	dir := os.Getenv("PODINFO_DIR")
+	if dir == "" {
+		dir = DefaultPodInfoDir
+	}
+	return Detect(&Config{PodInfoDir: dir, CgroupFile: "/proc/self/cgroup", MountInfoFile: "/proc/self/mountinfo"})
+})
+
+// Detect reads the pod information. Missing sources leave their values empty.
+func Detect(cfg *Config) *Info {
+	info := &Info{
+		PodName:       os.Getenv("POD_NAME"),
+		PodNamespace:  os.Getenv("POD_NAMESPACE"),
+		PodUID:        os.Getenv("POD_UID"),
+		PodIP:         os.Getenv("POD_IP"),
+		NodeName:      os.Getenv("NODE_NAME"),
+		ContainerName: os.Getenv("CONTAINER_NAME"),
+		Labels:        readPairs(filepath.Join(cfg.PodInfoDir, "labels")),
+		Annotations:   readPairs(filepath.Join(cfg.PodInfoDir, "annotations")),
+		Resources:     make(map[string]string),
+	}
+
+	for _, name := range []string{"cpu_limit", "cpu_request", "mem_limit", "mem_request"} {
+		if value := readValue(filepath.Join(cfg.PodInfoDir, name)); value != "" {
+			info.Resources[name] = value
+		}
+	}
+
+	info.ContainerID = containerID(cfg.CgroupFile, "")
+	if info.ContainerID == "" {
+		// With cgroup v2 namespaces the cgroup path is just "/", but the
+		// container directory is still mounted, e.g. for /etc/hostname.
+		info.ContainerID = containerID(cfg.MountInfoFile, "/containers/")
+	}
+	return info
+}
+
+// InCluster reports whether the service runs in a Kubernetes pod.
+func (i *Info) InCluster() bool {
+	return i.PodName != ""
+}
+
+// Attributes returns the OpenTelemetry resource attributes that are set.
+func (i *Info) Attributes() []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+	add := func(key attribute.Key, value string) {
+		if value != "" {
+			attributes = append(attributes, key.String(value))
+		}
+	}
+
+	add(semconv.K8SPodNameKey, i.PodName)
+	add(semconv.K8SNamespaceNameKey, i.PodNamespace)
+	add(semconv.K8SPodUIDKey, i.PodUID)
+	add(semconv.K8SNodeNameKey, i.NodeName)
+	add(semconv.K8SContainerNameKey, i.ContainerName)
+	add(semconv.ContainerIDKey, i.ContainerID)
+	return attributes
+}
+
+// LogFields returns the logger fields that are set, as key value pairs.
+func (i *Info) LogFields() []any {
+	var fields []any
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, key, value)
+		}
+	}
+
+	add("k8s_pod", i.PodName)
+	add("k8s_namespace", i.PodNamespace)
+	add("k8s_node", i.NodeName)
+	return fields
+}
+
+// ConstLabels returns the Prometheus constant labels that are set. They are
+// prefixed so they do not clash with the pod and namespace target labels
+// added by Kubernetes service discovery.
+func (i *Info) ConstLabels() prometheus.Labels {
+	labels := prometheus.Labels{}
+	add := func(name, value string) {
+		if value != "" {
+			labels[name] = value
+		}
+	}
+
+	add("k8s_pod_name", i.PodName)
+	add("k8s_namespace_name", i.PodNamespace)
+	add("k8s_node_name", i.NodeName)
+	return labels
+}
+
+func readValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readPairs parses a Downward API labels or annotations file, one
+// key="quoted value" pair per line.
+func readPairs(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	pairs := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		pairs[key] = value
+	}
+	return pairs
+}
+
+// containerID returns the container ID of the first line of path containing
+// marker that has one: the first ID following marker when set, as in
+// mountinfo, otherwise the last ID of the line, which ends the cgroup path.
+func containerID(path, marker string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, marker) {
+			continue
+		}
+
+		// In mountinfo the ID follows the marker; in cgroup it ends the path.
+		if marker != "" {
+			line = line[strings.Index(line, marker):]
+		}
+		if ids := containerIDPattern.FindAllString(line, -1); len(ids) > 0 {
+			if marker != "" {
+				return ids[0]
+			}
+			return ids[len(ids)-1]
+		}
+	}
+	return ""
+}
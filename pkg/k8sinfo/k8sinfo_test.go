@@ -0,0 +1,199 @@
+package k8sinfo
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	id        = "3f4e5d6c7b8a99887766554433221100ffeeddccbbaa00112233445566778899"
+	sandboxID = "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"
+)
+
+// writeFile writes content to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestContainerIDFromCgroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{
+			name:   "docker cgroup v1",
+			cgroup: "12:memory:/docker/" + id + "\n11:cpu,cpuacct:/docker/" + id + "\n",
+			want:   id,
+		},
+		{
+			name:   "containerd cgroupfs driver",
+			cgroup: "11:cpu,cpuacct:/kubepods/burstable/pod2c8f1e4a-6b0d-4d5e-9f3a-1b2c3d4e5f60/" + id + "\n",
+			want:   id,
+		},
+		{
+			name:   "containerd systemd driver",
+			cgroup: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod2c8f1e4a_6b0d_4d5e_9f3a_1b2c3d4e5f60.slice/cri-containerd-" + id + ".scope\n",
+			want:   id,
+		},
+		{
+			name:   "cri-o systemd driver",
+			cgroup: "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod2c8f1e4a_6b0d_4d5e_9f3a_1b2c3d4e5f60.slice/crio-" + id + ".scope\n",
+			want:   id,
+		},
+		{
+			// The container is nested below the pod sandbox, so the last ID
+			// of the path is taken.
+			name:   "nested below the sandbox",
+			cgroup: "0::/kubepods/" + sandboxID + "/" + id + "\n",
+			want:   id,
+		},
+		{
+			name:   "first line with an id",
+			cgroup: "1:name=systemd:/init.scope\n0::/system.slice/docker-" + id + ".scope\n",
+			want:   id,
+		},
+		{
+			name:   "cgroup namespace",
+			cgroup: "0::/\n",
+		},
+		{
+			name:   "short ids are ignored",
+			cgroup: "0::/kubepods/pod1/abc123\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerID(writeFile(t, "cgroup", tt.cgroup), ""); got != tt.want {
+				t.Errorf("containerID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerIDFromMountInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountinfo string
+		want      string
+	}{
+		{
+			name:      "docker",
+			mountinfo: "621 603 254:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n",
+			want:      id,
+		},
+		{
+			name:      "cri-o",
+			mountinfo: "1503 1484 0:24 /containers/storage/overlay-containers/" + id + "/userdata/hostname /etc/hostname rw,nosuid,nodev - tmpfs tmpfs rw\n",
+			want:      id,
+		},
+		{
+			// The sandbox ID precedes the marker and is skipped.
+			name:      "id after the marker",
+			mountinfo: "700 690 254:1 /var/lib/containerd/" + sandboxID + "/containers/" + id + "/resolv.conf /etc/resolv.conf rw - ext4 /dev/vda1 rw\n",
+			want:      id,
+		},
+		{
+			name: "lines without the marker are skipped",
+			mountinfo: "598 560 0:52 / / rw,relatime - overlay overlay rw,lowerdir=/var/lib/containerd/" + sandboxID + "/fs\n" +
+				"621 603 254:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw - ext4 /dev/vda1 rw\n",
+			want: id,
+		},
+		{
+			name:      "kubelet container directories carry no id",
+			mountinfo: "640 603 254:1 /var/lib/kubelet/pods/2c8f1e4a-6b0d-4d5e-9f3a-1b2c3d4e5f60/containers/app/3c9a7b21 /dev/termination-log rw - ext4 /dev/vda1 rw\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerID(writeFile(t, "mountinfo", tt.mountinfo), "/containers/"); got != tt.want {
+				t.Errorf("containerID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectContainerIDFallsBackToMountInfo(t *testing.T) {
+	info := Detect(&Config{
+		PodInfoDir:    t.TempDir(),
+		CgroupFile:    writeFile(t, "cgroup", "0::/\n"),
+		MountInfoFile: writeFile(t, "mountinfo", "621 603 254:1 /var/lib/docker/containers/"+id+"/hostname /etc/hostname rw - ext4 /dev/vda1 rw\n"),
+	})
+	if info.ContainerID != id {
+		t.Errorf("container ID = %q, want %q", info.ContainerID, id)
+	}
+}
+
+func TestReadPairs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "quoted values",
+			content: "app=\"k8s-demo\"\npod-template-hash=\"7d9f8b6c5\"\n",
+			want:    map[string]string{"app": "k8s-demo", "pod-template-hash": "7d9f8b6c5"},
+		},
+		{
+			name:    "prefixed keys",
+			content: "app.kubernetes.io/name=\"k8s-demo\"\n",
+			want:    map[string]string{"app.kubernetes.io/name": "k8s-demo"},
+		},
+		{
+			name:    "escaped newlines and quotes",
+			content: `description="first line\nsecond \"line\""` + "\n",
+			want:    map[string]string{"description": "first line\nsecond \"line\""},
+		},
+		{
+			name:    "json annotation",
+			content: `kubectl.kubernetes.io/last-applied-configuration="{\"kind\":\"Pod\"}\n"` + "\n",
+			want:    map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{\"kind\":\"Pod\"}\n"},
+		},
+		{
+			name:    "equals sign in value",
+			content: "query=\"a=b\"\n",
+			want:    map[string]string{"query": "a=b"},
+		},
+		{
+			name:    "empty value",
+			content: "empty=\"\"\n",
+			want:    map[string]string{"empty": ""},
+		},
+		{
+			name:    "unquoted value kept as is",
+			content: "plain=value\n",
+			want:    map[string]string{"plain": "value"},
+		},
+		{
+			name:    "lines without a separator are skipped",
+			content: "garbage\napp=\"k8s-demo\"\n\n",
+			want:    map[string]string{"app": "k8s-demo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readPairs(writeFile(t, "labels", tt.content)); !maps.Equal(got, tt.want) {
+				t.Errorf("readPairs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPairsMissingFile(t *testing.T) {
+	if got := readPairs(filepath.Join(t.TempDir(), "labels")); got != nil {
+		t.Errorf("readPairs = %q, want nil", got)
+	}
+}
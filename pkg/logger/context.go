@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+var nop = &Logger{
+	SugaredLogger: zap.NewNop().Sugar(),
+	state: &state{
+		levels:   NewLevels(zap.InfoLevel),
+		dropped:  newDroppedCounter(),
+		shutdown: func(context.Context) error { return nil },
+	},
+}
+
+// NewContext returns a copy of ctx carrying log.
+func NewContext(ctx context.Context, log *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx with the trace and span IDs
+// of the active span, so entries logged from child spans point at them. A
+// no-op logger is returned when ctx carries none.
+func FromContext(ctx context.Context) *Logger {
+	log, ok := ctx.Value(contextKey{}).(*Logger)
+	if !ok {
+		log = nop
+	}
+	return log.WithTrace(ctx)
+}
+
+// WithFields returns a copy of ctx whose logger carries the given fields,
+// for request attributes only known once a handler or middleware ran.
+func WithFields(ctx context.Context, keysAndValues ...any) context.Context {
+	log, ok := ctx.Value(contextKey{}).(*Logger)
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, log.With(keysAndValues...))
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"maps"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels holds the root log level and per named logger overrides, all of
+// which can be changed at runtime. An override applies to the named logger
+// and to the loggers named below it, so "http" also covers "http.access".
+type Levels struct {
+	root zap.AtomicLevel
+
+	mu        sync.RWMutex
+	overrides map[string]zap.AtomicLevel
+}
+
+func NewLevels(level zapcore.Level) *Levels {
+	return &Levels{
+		root:      zap.NewAtomicLevelAt(level),
+		overrides: make(map[string]zap.AtomicLevel),
+	}
+}
+
+func (l *Levels) Level() zapcore.Level {
+	return l.root.Level()
+}
+
+func (l *Levels) SetLevel(level zapcore.Level) {
+	l.root.SetLevel(level)
+}
+
+// Overrides returns the overridden level of every named logger.
+func (l *Levels) Overrides() map[string]zapcore.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	overrides := make(map[string]zapcore.Level, len(l.overrides))
+	for name, level := range l.overrides {
+		overrides[name] = level.Level()
+	}
+	return overrides
+}
+
+func (l *Levels) SetOverride(name string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if override, ok := l.overrides[name]; ok {
+		override.SetLevel(level)
+		return
+	}
+
+	overrides := maps.Clone(l.overrides)
+	overrides[name] = zap.NewAtomicLevelAt(level)
+	l.overrides = overrides
+}
+
+func (l *Levels) ClearOverride(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overrides := maps.Clone(l.overrides)
+	delete(overrides, name)
+	l.overrides = overrides
+}
+
+// Enabled reports whether any logger may log at level, letting zap skip
+// entries no logger wants before they are built.
+func (l *Levels) Enabled(level zapcore.Level) bool {
+	if l.root.Enabled(level) {
+		return true
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, override := range l.overrides {
+		if override.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnabledFor reports whether the logger with the given name logs at level,
+// using the override with the longest matching name.
+func (l *Levels) EnabledFor(name string, level zapcore.Level) bool {
+	l.mu.RLock()
+	overrides := l.overrides
+	l.mu.RUnlock()
+
+	for name != "" {
+		if override, ok := overrides[name]; ok {
+			return override.Enabled(level)
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return l.root.Enabled(level)
+}
+
+// levelCore applies the level of the named logger an entry was written to.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.EnabledFor(entry.LoggerName, entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -2,50 +2,208 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"os"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
 type Logger struct {
 	*zap.SugaredLogger
+	*state
 }
 
-func NewWithTracing(service string, version string) *Logger {
-	config := zap.NewProductionConfig()
-	config.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+// state is shared by a logger and every logger derived from it.
+type state struct {
+	levels   *Levels
+	sampler  *sampler
+	limiter  *messageLimiter
+	dropped  *prometheus.CounterVec
+	shutdown func(context.Context) error
+}
+
+type Config struct {
+	Service string
+	Version string
+	// Commit is added to every entry when set.
+	Commit string
+	// Fields are key value pairs added to every entry.
+	Fields []any
+	Level  string
+	// Format is json or console, defaulting to console in development.
+	Format string
+	// Development switches to a colourised, human readable encoder.
+	Development bool
+	// Sampling and RateLimit are disabled when nil and can be changed with
+	// SetSampling and SetRateLimit.
+	Sampling  *SamplingConfig
+	RateLimit *RateLimitConfig
+	// OTLP additionally exports log records when set.
+	OTLP *OTLPConfig
+}
+
+func New(cfg *Config) (*Logger, error) {
+	level := zapcore.InfoLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+		}
+	}
+
+	format := cfg.Format
+	if format == "" {
+		format = FormatJSON
+		if cfg.Development {
+			format = FormatConsole
+		}
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	if cfg.Development {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+
+	encoderConfig.LevelKey = "level"
+	encoderConfig.CallerKey = "caller"
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.MessageKey = "message"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	switch format {
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		if cfg.Development {
+			encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q: expected %s or %s", format, FormatJSON, FormatConsole)
+	}
+
+	levels := NewLevels(level)
+
+	var core zapcore.Core = zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), levels)
+
+	shutdown := func(context.Context) error { return nil }
+	if cfg.OTLP != nil {
+		otlpCore, provider, err := newOTLPCore(cfg.Service, cfg.OTLP)
+		if err != nil {
+			return nil, err
+		}
+		core = zapcore.NewTee(core, otlpCore)
+		shutdown = provider.Shutdown
+	}
+
+	dropped := newDroppedCounter()
+
+	sampler := &sampler{}
+	sampler.set(cfg.Sampling)
+	limiter := &messageLimiter{}
+	limiter.set(cfg.RateLimit)
 
-	config.InitialFields = map[string]any{
-		"service": service,
-		"version": version,
-		"pid":     os.Getpid(),
+	core = newRedactCore(core, encoderConfig)
+	core = &samplerCore{Core: core, sampler: sampler, dropped: dropped}
+	core = &rateLimitCore{Core: core, limiter: limiter, dropped: dropped}
+	core = &levelCore{Core: core, levels: levels}
+
+	fields := []zap.Field{
+		zap.String("service", cfg.Service),
+		zap.String("version", cfg.Version),
+		zap.Int("pid", os.Getpid()),
+	}
+	if cfg.Commit != "" {
+		fields = append(fields, zap.String("commit", cfg.Commit))
 	}
 
-	config.EncoderConfig.LevelKey = "level"
-	config.EncoderConfig.CallerKey = "caller"
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.MessageKey = "message"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	logger := zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.Fields(fields...),
+	)
 
-	logger, err := config.Build()
+	return &Logger{
+		SugaredLogger: logger.Sugar().With(cfg.Fields...),
+		state: &state{
+			levels:   levels,
+			sampler:  sampler,
+			limiter:  limiter,
+			dropped:  dropped,
+			shutdown: shutdown,
+		},
+	}, nil
+}
+
+func NewWithTracing(service string, version string) *Logger {
+	logger, err := New(&Config{
+		Service: service,
+		Version: version,
+	})
 	if err != nil {
 		panic(err)
 	}
+	return logger
+}
+
+// Shutdown flushes and stops the OTLP log export, if enabled. Entries logged
+// afterwards are only written to stdout.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	return l.shutdown(ctx)
+}
 
-	return &Logger{logger.Sugar()}
+// Collector exposes the number of entries dropped by sampling and rate
+// limiting.
+func (l *Logger) Collector() prometheus.Collector {
+	return l.dropped
+}
+
+// Levels returns the runtime adjustable levels shared by the logger and every
+// logger derived from it.
+func (l *Logger) Levels() *Levels {
+	return l.levels
+}
+
+// SetSampling replaces the sampling settings of the logger and every logger
+// derived from it; nil disables sampling.
+func (l *Logger) SetSampling(cfg *SamplingConfig) {
+	l.sampler.set(cfg)
+}
+
+// SetRateLimit replaces the rate limit of the logger and every logger derived
+// from it, resetting the per message buckets; nil disables it.
+func (l *Logger) SetRateLimit(cfg *RateLimitConfig) {
+	l.limiter.set(cfg)
+}
+
+// Named returns a child logger whose level can be overridden by name.
+func (l *Logger) Named(name string) *Logger {
+	return &Logger{SugaredLogger: l.SugaredLogger.Named(name), state: l.state}
+}
+
+// With returns a child logger carrying the given fields.
+func (l *Logger) With(keysAndValues ...any) *Logger {
+	return &Logger{SugaredLogger: l.SugaredLogger.With(keysAndValues...), state: l.state}
 }
 
 func (l *Logger) WithTrace(ctx context.Context) *Logger {
 	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
-		return &Logger{
-			l.SugaredLogger.With(
-				"trace_id", span.SpanContext().TraceID().String(),
-				"span_id", span.SpanContext().SpanID().String(),
-			),
-		}
+		return l.With(
+			"trace_id", span.SpanContext().TraceID().String(),
+			"span_id", span.SpanContext().SpanID().String(),
+			traceContext(span.SpanContext()),
+		)
 	}
 	return l
 }
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultLogsPath = "/v1/logs"
+
+// OTLPConfig enables exporting log records over OTLP/HTTP next to the
+// stdout output.
+type OTLPConfig struct {
+	// Endpoint is the collector URL; http disables TLS.
+	Endpoint string
+	Headers  map[string]string
+	// Level is the minimum level exported, independently of the stdout
+	// level. Entries must still pass the logger levels.
+	Level string
+	// Resource should be the tracer provider resource so logs and traces
+	// describe the same service.
+	Resource *resource.Resource
+}
+
+func newOTLPCore(service string, cfg *OTLPConfig) (zapcore.Core, *sdklog.LoggerProvider, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, nil, fmt.Errorf("invalid otlp logs endpoint %q: expected an http or https URL", cfg.Endpoint)
+	}
+
+	level := zapcore.InfoLevel
+	if cfg.Level != "" {
+		if level, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return nil, nil, fmt.Errorf("invalid otlp log level %q: %w", cfg.Level, err)
+		}
+	}
+
+	path := endpoint.Path
+	if path == "" || path == "/" {
+		path = defaultLogsPath
+	}
+
+	options := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(endpoint.Host),
+		otlploghttp.WithURLPath(path),
+		otlploghttp.WithHeaders(cfg.Headers),
+	}
+	if endpoint.Scheme == "http" {
+		options = append(options, otlploghttp.WithInsecure())
+	}
+
+	exporter, err := otlploghttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create otlp logs exporter: %w", err)
+	}
+
+	providerOptions := []sdklog.LoggerProviderOption{
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter, sdklog.WithExportInterval(time.Second*5))),
+	}
+	if cfg.Resource != nil {
+		providerOptions = append(providerOptions, sdklog.WithResource(cfg.Resource))
+	}
+	provider := sdklog.NewLoggerProvider(providerOptions...)
+
+	core := otelzap.NewCore(service, otelzap.WithLoggerProvider(provider))
+	return &minLevelCore{Core: core, level: level}, provider, nil
+}
+
+// traceContext is a field the stdout encoder skips but from which the OTLP
+// core takes the span context, correlating exported records with the trace.
+func traceContext(spanContext trace.SpanContext) zap.Field {
+	return zap.Field{
+		Key:       "otel_context",
+		Type:      zapcore.SkipType,
+		Interface: trace.ContextWithSpanContext(context.Background(), spanContext),
+	}
+}
+
+// minLevelCore drops entries below level before they reach the wrapped core.
+type minLevelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c *minLevelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level && c.Core.Enabled(level)
+}
+
+func (c *minLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &minLevelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *minLevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.level {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+func (c *minLevelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level < c.level {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type credentials struct {
+	user     string
+	password string
+}
+
+func (c credentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", c.user)
+	enc.AddString("password", c.password)
+	enc.AddDuration("ttl", time.Second)
+	return nil
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bearer token", "Authorization: Bearer abc.def-ghi", "Authorization: Bearer [REDACTED]"},
+		{"dsn password", "postgres://user:hunter2@db:5432/app", "postgres://user:[REDACTED]@db:5432/app"},
+		{"url without password", "https://example.com/path?q=1", "https://example.com/path?q=1"},
+		{"plain text", "nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.input); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactCoreFields(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Cookie", "session=abc")
+	header.Set("Accept", "application/json")
+
+	tests := []struct {
+		name  string
+		field zap.Field
+		want  any
+	}{
+		{"sensitive key", zap.String("db_password", "hunter2"), redacted},
+		{"string value", zap.String("dsn", "postgres://u:hunter2@db/app"), "postgres://u:[REDACTED]@db/app"},
+		{"byte string", zap.ByteString("dsn", []byte("postgres://u:hunter2@db/app")), "postgres://u:[REDACTED]@db/app"},
+		{"stringer", zap.Stringer("header", stringer("Bearer abc")), "Bearer [REDACTED]"},
+		{"error", zap.NamedError("error", errString("dial postgres://u:hunter2@db/app")), "dial postgres://u:[REDACTED]@db/app"},
+		{"http header", zap.Any("headers", header), map[string]any{
+			"Authorization": redacted,
+			"Cookie":        redacted,
+			"Accept":        []any{"application/json"},
+		}},
+		{"string map", zap.Any("params", map[string]string{"api_key": "abc", "dsn": "postgres://u:p@db/app"}), map[string]any{
+			"api_key": redacted,
+			"dsn":     "postgres://u:[REDACTED]@db/app",
+		}},
+		{"object", zap.Object("credentials", credentials{user: "admin", password: "hunter2"}), map[string]any{
+			"user":     "admin",
+			"password": redacted,
+			"ttl":      "1", // json.Number, seconds as in production
+		}},
+		{"array", zap.Strings("urls", []string{"postgres://u:p@db/app", "http://example.com"}), []any{
+			"postgres://u:[REDACTED]@db/app",
+			"http://example.com",
+		}},
+		{"unaffected int", zap.Int("count", 3), int64(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observed, logs := observer.New(zapcore.DebugLevel)
+			core := newRedactCore(observed, zap.NewProductionEncoderConfig())
+
+			zap.New(core).Info("entry", tt.field)
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+
+			got, ok := entries[0].ContextMap()[tt.field.Key]
+			if !ok {
+				t.Fatalf("field %q missing from %v", tt.field.Key, entries[0].ContextMap())
+			}
+			if normalized := normalize(got); !reflect.DeepEqual(normalized, tt.want) {
+				t.Errorf("field %q = %#v, want %#v", tt.field.Key, normalized, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactCoreWith(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(newRedactCore(observed, zap.NewProductionEncoderConfig()))
+
+	log.With(zap.Any("headers", map[string]string{"Authorization": "Bearer abc"})).
+		Info("connecting to postgres://u:hunter2@db/app")
+
+	entry := logs.All()[0]
+	if strings.Contains(entry.Message, "hunter2") {
+		t.Errorf("message not redacted: %q", entry.Message)
+	}
+	if headers := normalize(entry.ContextMap()["headers"]); !reflect.DeepEqual(headers, map[string]any{"Authorization": redacted}) {
+		t.Errorf("headers = %#v, want the authorization redacted", headers)
+	}
+}
+
+func TestRedactCoreInline(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	zap.New(newRedactCore(observed, zap.NewProductionEncoderConfig())).
+		Info("login", zap.Inline(credentials{user: "admin", password: "hunter2"}))
+
+	fields := logs.All()[0].ContextMap()
+	if fields["user"] != "admin" || fields["password"] != redacted {
+		t.Errorf("inlined fields = %v, want the password redacted", fields)
+	}
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+// normalize converts the values the observer records to the types decoded
+// from JSON, so expectations can be written once.
+func normalize(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(value))
+		for key, nested := range value {
+			normalized[key] = normalize(nested)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(value))
+		for i, nested := range value {
+			normalized[i] = normalize(nested)
+		}
+		return normalized
+	case interface{ String() string }:
+		return value.String()
+	}
+	return value
+}
@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	reasonSampled     = "sampled"
+	reasonRateLimited = "rate_limited"
+
+	maxRateLimitedMessages = 1024
+
+	samplingCounters = 4096
+	samplingLevels   = int(zapcore.FatalLevel-zapcore.DebugLevel) + 1
+)
+
+// SamplingConfig logs the first Initial entries with the same level and
+// message every Tick, then every Thereafter-th of them.
+type SamplingConfig struct {
+	Tick       time.Duration
+	Initial    int
+	Thereafter int
+}
+
+// RateLimitConfig allows each message Rate entries per second with bursts of
+// up to Burst entries, whatever its level.
+type RateLimitConfig struct {
+	Rate  float64
+	Burst int
+}
+
+// newDroppedCounter creates the counter with every series at zero, so it is
+// exposed, and rates can be computed, before an entry is dropped.
+func newDroppedCounter() *prometheus.CounterVec {
+	dropped := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_entries_dropped_total",
+		Help: "Total number of log entries dropped by sampling or rate limiting",
+	}, []string{"reason", "level"})
+
+	for _, reason := range []string{reasonSampled, reasonRateLimited} {
+		for level := zapcore.DebugLevel; level <= zapcore.FatalLevel; level++ {
+			dropped.WithLabelValues(reason, level.String())
+		}
+	}
+	return dropped
+}
+
+// sampler counts entries per level and message hash, like the zap sampler,
+// but its settings can be replaced while logging. A nil or zero Initial
+// setting disables it.
+type sampler struct {
+	settings atomic.Pointer[SamplingConfig]
+	counters [samplingLevels][samplingCounters]samplingCounter
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func (s *sampler) set(cfg *SamplingConfig) {
+	if cfg != nil && cfg.Tick <= 0 {
+		cfg = &SamplingConfig{Tick: time.Second, Initial: cfg.Initial, Thereafter: cfg.Thereafter}
+	}
+	s.settings.Store(cfg)
+}
+
+func (s *sampler) allow(entry zapcore.Entry) bool {
+	cfg := s.settings.Load()
+	if cfg == nil || cfg.Initial <= 0 || entry.Level < zapcore.DebugLevel || entry.Level > zapcore.FatalLevel {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(entry.Message))
+	counter := &s.counters[entry.Level-zapcore.DebugLevel][hash.Sum32()%samplingCounters]
+
+	n := counter.inc(entry.Time, cfg.Tick)
+	if n <= uint64(cfg.Initial) {
+		return true
+	}
+	return cfg.Thereafter > 0 && (n-uint64(cfg.Initial))%uint64(cfg.Thereafter) == 0
+}
+
+func (c *samplingCounter) inc(now time.Time, tick time.Duration) uint64 {
+	tn := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	newResetAt := tn + tick.Nanoseconds()
+	if !c.resetAt.CompareAndSwap(resetAt, newResetAt) {
+		// Another goroutine reset the counter first.
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+type samplerCore struct {
+	zapcore.Core
+	sampler *sampler
+	dropped *prometheus.CounterVec
+}
+
+func (c *samplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplerCore{Core: c.Core.With(fields), sampler: c.sampler, dropped: c.dropped}
+}
+
+func (c *samplerCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+
+	if !c.sampler.allow(entry) {
+		c.dropped.WithLabelValues(reasonSampled, entry.Level.String()).Inc()
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// rateLimitCore drops entries whose message exceeded its rate. Messages past
+// the first maxRateLimitedMessages seen are not limited, bounding memory when
+// messages are built from request data.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *messageLimiter
+	dropped *prometheus.CounterVec
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter, dropped: c.dropped}
+}
+
+func (c *rateLimitCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+
+	if !c.limiter.allow(entry.Message) {
+		c.dropped.WithLabelValues(reasonRateLimited, entry.Level.String()).Inc()
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// messageLimiter keeps a token bucket per message. A zero rate disables it.
+type messageLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func (l *messageLimiter) set(cfg *RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate, l.burst = 0, 0
+	if cfg != nil && cfg.Rate > 0 {
+		l.rate = cfg.Rate
+		l.burst = float64(cfg.Burst)
+		if l.burst < 1 {
+			l.burst = max(1, cfg.Rate)
+		}
+	}
+	l.buckets = make(map[string]*tokenBucket)
+}
+
+func (l *messageLimiter) allow(message string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	bucket, ok := l.buckets[message]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitedMessages {
+			return true
+		}
+		bucket = &tokenBucket{tokens: l.burst, lastRefill: now}
+		l.buckets[message] = bucket
+	}
+
+	bucket.tokens = min(l.burst, bucket.tokens+l.rate*now.Sub(bucket.lastRefill).Seconds())
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
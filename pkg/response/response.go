@@ -3,6 +3,9 @@ package response
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SuccessResponse struct {
@@ -17,6 +20,8 @@ type ErrorResponse struct {
 	Message   string `json:"message"`
 	ErrorCode string `json:"errorCode"`
 	Details   any    `json:"details,omitempty"`
+	TraceID   string `json:"traceId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
 func RespondSuccess(w http.ResponseWriter, code int, msg string, data any) {
@@ -24,8 +29,22 @@ func RespondSuccess(w http.ResponseWriter, code int, msg string, data any) {
 	respond(w, code, response)
 }
 
-func RespondError(w http.ResponseWriter, status int, code, msg string, details any) {
-	response := ErrorResponse{Success: false, Code: status, Message: msg, ErrorCode: code, Details: details}
+// RespondError writes an error body carrying the request and trace IDs of r,
+// so a reported error can be looked up in the logs and in Jaeger.
+func RespondError(w http.ResponseWriter, r *http.Request, status int, code, msg string, details any) {
+	response := ErrorResponse{
+		Success:   false,
+		Code:      status,
+		Message:   msg,
+		ErrorCode: code,
+		Details:   details,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsSampled() {
+		response.TraceID = spanContext.TraceID().String()
+	}
+
 	respond(w, status, response)
 }
 
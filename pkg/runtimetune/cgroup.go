@@ -0,0 +1,137 @@
+package runtimetune
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unlimitedMemory is the threshold above which a cgroup v1 memory limit is
+// the "no limit" value, which is rounded to the page size.
+const unlimitedMemory = 1 << 62
+
+// Limits are the container resource limits; zero means unlimited.
+type Limits struct {
+	// CPU is the CPU quota in cores, which may be fractional.
+	CPU float64 `json:"cpu"`
+	// Memory is the memory limit in bytes.
+	Memory int64 `json:"memoryBytes"`
+	// CgroupVersion is 1 or 2, 0 when no cgroup was found.
+	CgroupVersion int `json:"cgroupVersion"`
+}
+
+// ReadLimits reads the limits of the cgroup of the current process, given
+// where cgroupfs is mounted and the /proc/self/cgroup file.
+func ReadLimits(root, procCgroup string) (*Limits, error) {
+	paths, err := cgroupPaths(procCgroup)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return readV2(root, paths[""])
+	}
+	return readV1(root, paths)
+}
+
+// cgroupPaths maps each cgroup v1 controller, or "" for cgroup v2, to the
+// cgroup path of the process.
+func cgroupPaths(procCgroup string) (map[string]string, error) {
+	file, err := os.Open(procCgroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process cgroup: %w", err)
+	}
+	defer file.Close()
+
+	paths := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for controller := range strings.SplitSeq(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths, scanner.Err()
+}
+
+func readV2(root, path string) (*Limits, error) {
+	limits := &Limits{CgroupVersion: 2}
+
+	// Inside a cgroup namespace the container cgroup is mounted at the root
+	// and path is "/"; otherwise it is below the root.
+	dir := findDir(root, path, "cpu.max")
+
+	if fields := strings.Fields(readFile(filepath.Join(dir, "cpu.max"))); len(fields) == 2 && fields[0] != "max" {
+		quota, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu.max quota %q: %w", fields[0], err)
+		}
+		period, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("invalid cpu.max period %q", fields[1])
+		}
+		limits.CPU = quota / period
+	}
+
+	if value := readFile(filepath.Join(findDir(root, path, "memory.max"), "memory.max")); value != "" && value != "max" {
+		memory, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory.max %q: %w", value, err)
+		}
+		limits.Memory = memory
+	}
+	return limits, nil
+}
+
+func readV1(root string, paths map[string]string) (*Limits, error) {
+	cpuPath, hasCPU := paths["cpu"]
+	memoryPath, hasMemory := paths["memory"]
+	if !hasCPU && !hasMemory {
+		return nil, errors.New("no cgroup cpu or memory controller found")
+	}
+	limits := &Limits{CgroupVersion: 1}
+
+	if hasCPU {
+		dir := findDir(filepath.Join(root, "cpu"), cpuPath, "cpu.cfs_quota_us")
+		quota, _ := strconv.ParseFloat(readFile(filepath.Join(dir, "cpu.cfs_quota_us")), 64)
+		period, _ := strconv.ParseFloat(readFile(filepath.Join(dir, "cpu.cfs_period_us")), 64)
+		if quota > 0 && period > 0 {
+			limits.CPU = quota / period
+		}
+	}
+
+	if hasMemory {
+		dir := findDir(filepath.Join(root, "memory"), memoryPath, "memory.limit_in_bytes")
+		memory, _ := strconv.ParseInt(readFile(filepath.Join(dir, "memory.limit_in_bytes")), 10, 64)
+		if memory > 0 && memory < unlimitedMemory {
+			limits.Memory = memory
+		}
+	}
+	return limits, nil
+}
+
+// findDir returns the directory of the process cgroup below root if it holds
+// file, else root itself, as in containers the cgroup of the process is
+// usually mounted at root while /proc/self/cgroup shows its host path.
+func findDir(root, path, file string) string {
+	dir := filepath.Join(root, path)
+	if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+		return dir
+	}
+	return root
+}
+
+func readFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,189 @@
+package runtimetune
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files, keyed by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	const (
+		v1Cgroup = "12:memory:/kubepods/pod1/abc\n11:cpu,cpuacct:/kubepods/pod1/abc\n1:name=systemd:/kubepods/pod1/abc\n"
+		v2Cgroup = "0::/\n"
+	)
+
+	tests := []struct {
+		name       string
+		procCgroup string
+		files      map[string]string
+		want       Limits
+		wantErr    bool
+	}{
+		{
+			name:       "v2 limits in namespace",
+			procCgroup: v2Cgroup,
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "536870912\n",
+			},
+			want: Limits{CPU: 1.5, Memory: 512 << 20, CgroupVersion: 2},
+		},
+		{
+			name:       "v2 unlimited",
+			procCgroup: v2Cgroup,
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+			},
+			want: Limits{CgroupVersion: 2},
+		},
+		{
+			name:       "v2 nested cgroup path",
+			procCgroup: "0::/kubepods/pod1/abc\n",
+			files: map[string]string{
+				"cgroup.controllers":             "cpu memory",
+				"cpu.max":                        "max 100000\n",
+				"memory.max":                     "max\n",
+				"kubepods/pod1/abc/cpu.max":      "50000 100000\n",
+				"kubepods/pod1/abc/memory.max":   "268435456\n",
+				"kubepods/pod1/abc/cgroup.procs": "1\n",
+			},
+			want: Limits{CPU: 0.5, Memory: 256 << 20, CgroupVersion: 2},
+		},
+		{
+			name:       "v2 files missing",
+			procCgroup: v2Cgroup,
+			files:      map[string]string{"cgroup.controllers": ""},
+			want:       Limits{CgroupVersion: 2},
+		},
+		{
+			name:       "v2 invalid quota",
+			procCgroup: v2Cgroup,
+			files: map[string]string{
+				"cgroup.controllers": "cpu",
+				"cpu.max":            "lots 100000\n",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "v2 invalid memory",
+			procCgroup: v2Cgroup,
+			files: map[string]string{
+				"cgroup.controllers": "memory",
+				"memory.max":         "512M\n",
+			},
+			wantErr: true,
+		},
+		{
+			// The host path exists but holds no limits, as in a cgroup
+			// namespace, so they are read from the controller root.
+			name:       "v1 limits mounted at controller root",
+			procCgroup: v1Cgroup,
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":                  "200000\n",
+				"cpu/cpu.cfs_period_us":                 "100000\n",
+				"memory/memory.limit_in_bytes":          "1073741824\n",
+				"cpu/kubepods/pod1/abc/cgroup.procs":    "1\n",
+				"memory/kubepods/pod1/abc/cgroup.procs": "1\n",
+			},
+			want: Limits{CPU: 2, Memory: 1 << 30, CgroupVersion: 1},
+		},
+		{
+			name:       "v1 limits below host path",
+			procCgroup: v1Cgroup,
+			files: map[string]string{
+				"cpu/kubepods/pod1/abc/cpu.cfs_quota_us":         "25000\n",
+				"cpu/kubepods/pod1/abc/cpu.cfs_period_us":        "100000\n",
+				"memory/kubepods/pod1/abc/memory.limit_in_bytes": "134217728\n",
+			},
+			want: Limits{CPU: 0.25, Memory: 128 << 20, CgroupVersion: 1},
+		},
+		{
+			name:       "v1 unlimited",
+			procCgroup: v1Cgroup,
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			want: Limits{CgroupVersion: 1},
+		},
+		{
+			name:       "v1 without cpu or memory controller",
+			procCgroup: "1:name=systemd:/\n",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			root := filepath.Join(dir, "cgroup")
+			procCgroup := filepath.Join(dir, "proc-self-cgroup")
+
+			if err := os.MkdirAll(root, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeFiles(t, root, tt.files)
+			writeFiles(t, dir, map[string]string{"proc-self-cgroup": tt.procCgroup})
+
+			limits, err := ReadLimits(root, procCgroup)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", limits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *limits != tt.want {
+				t.Errorf("got %+v, want %+v", *limits, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLimitsWithoutProcCgroup(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ReadLimits(dir, filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing process cgroup file")
+	}
+}
+
+func TestMaxProcs(t *testing.T) {
+	tests := []struct {
+		cpu  float64
+		want int
+	}{
+		{cpu: 0.1, want: 1},
+		{cpu: 1, want: 1},
+		{cpu: 1.5, want: 2},
+		{cpu: 2, want: 2},
+	}
+
+	for _, tt := range tests {
+		// Results are capped at the CPUs of the machine running the test.
+		want := min(tt.want, maxProcs(1e6))
+		if got := maxProcs(tt.cpu); got != want {
+			t.Errorf("maxProcs(%v) = %d, want %d", tt.cpu, got, want)
+		}
+	}
+}
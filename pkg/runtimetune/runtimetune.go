@@ -0,0 +1,146 @@
+// Package runtimetune sets GOMAXPROCS and GOMEMLIMIT from the CPU and memory
+// limits of the container, which the Go runtime does not otherwise account
+// for, leading to CFS throttling and OOM kills.
+package runtimetune
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sources of the applied values.
+const (
+	SourceCgroup  = "cgroup"
+	SourceEnv     = "env"
+	SourceDefault = "default"
+)
+
+type Config struct {
+	// SetMaxProcs sets GOMAXPROCS from the CPU limit.
+	SetMaxProcs bool
+	// MemoryLimitRatio is the fraction of the memory limit used as
+	// GOMEMLIMIT, leaving headroom for memory the Go runtime does not
+	// manage; 0 disables it.
+	MemoryLimitRatio float64
+	// CgroupRoot and ProcCgroup default to /sys/fs/cgroup and
+	// /proc/self/cgroup.
+	CgroupRoot string
+	ProcCgroup string
+}
+
+// Decision records the container limits and the runtime settings applied.
+type Decision struct {
+	Limits           *Limits `json:"limits,omitempty"`
+	LimitsError      string  `json:"limitsError,omitempty"`
+	GOMAXPROCS       int     `json:"gomaxprocs"`
+	GOMAXPROCSSource string  `json:"gomaxprocsSource"`
+	GOMEMLIMIT       int64   `json:"gomemlimit"`
+	GOMEMLIMITSource string  `json:"gomemlimitSource"`
+}
+
+var applied atomic.Pointer[Decision]
+
+// Apply reads the container limits and sets the runtime accordingly. Values
+// set with the GOMAXPROCS and GOMEMLIMIT environment variables are kept. An
+// unreadable cgroup leaves the runtime defaults and is reported in the
+// decision rather than failing.
+func Apply(cfg *Config) *Decision {
+	root, procCgroup := cfg.CgroupRoot, cfg.ProcCgroup
+	if root == "" {
+		root = "/sys/fs/cgroup"
+	}
+	if procCgroup == "" {
+		procCgroup = "/proc/self/cgroup"
+	}
+
+	decision := &Decision{GOMAXPROCSSource: SourceDefault, GOMEMLIMITSource: SourceDefault}
+
+	limits, err := ReadLimits(root, procCgroup)
+	if err != nil {
+		decision.LimitsError = err.Error()
+	} else {
+		decision.Limits = limits
+	}
+
+	switch {
+	case os.Getenv("GOMAXPROCS") != "":
+		decision.GOMAXPROCSSource = SourceEnv
+	case cfg.SetMaxProcs && limits != nil && limits.CPU > 0:
+		runtime.GOMAXPROCS(maxProcs(limits.CPU))
+		decision.GOMAXPROCSSource = SourceCgroup
+	}
+
+	switch {
+	case os.Getenv("GOMEMLIMIT") != "":
+		decision.GOMEMLIMITSource = SourceEnv
+	case cfg.MemoryLimitRatio > 0 && limits != nil && limits.Memory > 0:
+		debug.SetMemoryLimit(int64(float64(limits.Memory) * cfg.MemoryLimitRatio))
+		decision.GOMEMLIMITSource = SourceCgroup
+	}
+
+	decision.GOMAXPROCS = runtime.GOMAXPROCS(0)
+	decision.GOMEMLIMIT = debug.SetMemoryLimit(-1)
+	applied.Store(decision)
+	return decision
+}
+
+// Applied returns the last decision of Apply, nil if it was never called.
+func Applied() *Decision {
+	return applied.Load()
+}
+
+// maxProcs rounds a fractional quota up, so a 1.5 CPU limit uses two
+// threads, without exceeding the CPUs of the machine.
+func maxProcs(cpu float64) int {
+	return max(1, min(runtime.NumCPU(), int(math.Ceil(cpu))))
+}
+
+// Collector exposes the container limits and where GOMAXPROCS and GOMEMLIMIT
+// came from. Their values are already exported by the Go collector as
+// go_sched_gomaxprocs_threads and go_gc_gomemlimit_bytes.
+func Collector() prometheus.Collector {
+	return &collector{
+		cpuLimit: prometheus.NewDesc("container_cpu_limit_cores",
+			"CPU limit of the container read from its cgroup, 0 when unlimited", nil, nil),
+		memoryLimit: prometheus.NewDesc("container_memory_limit_bytes",
+			"Memory limit of the container read from its cgroup, 0 when unlimited", nil, nil),
+		info: prometheus.NewDesc("runtime_tuning_info",
+			"Sources of GOMAXPROCS and GOMEMLIMIT, always 1",
+			[]string{"gomaxprocs_source", "gomemlimit_source", "cgroup_version"}, nil),
+	}
+}
+
+type collector struct {
+	cpuLimit    *prometheus.Desc
+	memoryLimit *prometheus.Desc
+	info        *prometheus.Desc
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpuLimit
+	ch <- c.memoryLimit
+	ch <- c.info
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	decision := Applied()
+	if decision == nil {
+		return
+	}
+
+	var limits Limits
+	if decision.Limits != nil {
+		limits = *decision.Limits
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.cpuLimit, prometheus.GaugeValue, limits.CPU)
+	ch <- prometheus.MustNewConstMetric(c.memoryLimit, prometheus.GaugeValue, float64(limits.Memory))
+	ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1,
+		decision.GOMAXPROCSSource, decision.GOMEMLIMITSource, strconv.Itoa(limits.CgroupVersion))
+}